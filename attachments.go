@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const (
+	maxAttachmentBytes = 5 << 20
+
+	// maxImagePixels guards against small files that decode into huge
+	// bitmaps. For an animated GIF it bounds the pixels of all frames
+	// together.
+	maxImagePixels = 40_000_000
+
+	maxGIFFrames = 500
+)
+
+// attachmentExtensions lists the accepted image types and the extension
+// their stored files get.
+var attachmentExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+var (
+	errUnsupportedImage = errors.New("unsupported image type")
+	errImageTooLarge    = errors.New("image dimensions too large")
+)
+
+type sanitizedImage struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// sanitizeImage checks that data really is a supported image, whatever the
+// client claimed, and re-encodes it. Only pixel data survives re-encoding,
+// so EXIF blocks (including GPS coordinates) and any other metadata in the
+// upload are dropped.
+func sanitizeImage(data []byte) (sanitizedImage, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := attachmentExtensions[contentType]; !ok {
+		return sanitizedImage{}, errUnsupportedImage
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return sanitizedImage{}, errUnsupportedImage
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return sanitizedImage{}, errImageTooLarge
+	}
+
+	var buf bytes.Buffer
+	switch contentType {
+	case "image/jpeg":
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return sanitizedImage{}, errUnsupportedImage
+		}
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90})
+		if err != nil {
+			return sanitizedImage{}, err
+		}
+	case "image/png":
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return sanitizedImage{}, errUnsupportedImage
+		}
+		if err := png.Encode(&buf, img); err != nil {
+			return sanitizedImage{}, err
+		}
+	case "image/gif":
+		if err := checkGIFFrames(data); err != nil {
+			return sanitizedImage{}, err
+		}
+		img, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return sanitizedImage{}, errUnsupportedImage
+		}
+		if err := gif.EncodeAll(&buf, img); err != nil {
+			return sanitizedImage{}, err
+		}
+	}
+
+	return sanitizedImage{
+		Data:        buf.Bytes(),
+		ContentType: contentType,
+		Width:       config.Width,
+		Height:      config.Height,
+	}, nil
+}
+
+// checkGIFFrames walks the blocks of a GIF without decoding any pixels and
+// refuses files with too many frames or too many pixels across all frames.
+// DecodeConfig only reports the logical screen size, and every frame of a
+// highly compressible GIF can be as large as that.
+func checkGIFFrames(data []byte) error {
+	// Header (6 bytes) and logical screen descriptor (7 bytes).
+	if len(data) < 13 {
+		return errUnsupportedImage
+	}
+	pos := 13
+	if data[10]&0x80 != 0 {
+		pos += 3 << (data[10]&0x07 + 1)
+	}
+
+	// skipSubBlocks moves pos past a chain of data sub-blocks.
+	skipSubBlocks := func() error {
+		for {
+			if pos >= len(data) {
+				return errUnsupportedImage
+			}
+			size := int(data[pos])
+			pos++
+			if size == 0 {
+				return nil
+			}
+			pos += size
+		}
+	}
+
+	frames, pixels := 0, 0
+	for pos < len(data) {
+		switch data[pos] {
+		case 0x21: // extension: label, then sub-blocks
+			pos += 2
+			if err := skipSubBlocks(); err != nil {
+				return err
+			}
+		case 0x2C: // image descriptor
+			if pos+10 > len(data) {
+				return errUnsupportedImage
+			}
+			width := int(data[pos+5]) | int(data[pos+6])<<8
+			height := int(data[pos+7]) | int(data[pos+8])<<8
+			flags := data[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << (flags&0x07 + 1)
+			}
+
+			frames++
+			pixels += width * height
+			if frames > maxGIFFrames || pixels > maxImagePixels {
+				return errImageTooLarge
+			}
+
+			// LZW minimum code size, then the image data.
+			pos++
+			if err := skipSubBlocks(); err != nil {
+				return err
+			}
+		case 0x3B: // trailer
+			return nil
+		default:
+			return errUnsupportedImage
+		}
+	}
+	return errUnsupportedImage
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"github/anansi-1/Chirpy/internal/filter"
+	"net/http"
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// FieldError describes one problem with one request field. Code is stable
+// for clients to switch on; Message is for humans.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// validateChirpBody is the single validation pipeline for chirp text. It
+// rejects empty and over-long bodies and runs the content filter, and
+// returns the filter result whose Body is what should be stored. field
+// names the request field in any errors.
+//
+// Length is counted in grapheme clusters, i.e. what a reader sees as
+// characters: an emoji with skin-tone modifiers or a letter with a
+// combining accent counts once.
+func (cfg *apiConfig) validateChirpBody(body, field string, maxLength int) (filter.Result, []FieldError) {
+	var errs []FieldError
+
+	if strings.TrimSpace(body) == "" {
+		errs = append(errs, FieldError{
+			Field:   field,
+			Code:    "required",
+			Message: "Chirp body is required",
+		})
+		return filter.Result{}, errs
+	}
+
+	if uniseg.GraphemeClusterCount(body) > maxLength {
+		errs = append(errs, FieldError{
+			Field:   field,
+			Code:    "too_long",
+			Message: "Chirp is too long",
+		})
+	}
+
+	result := cfg.filter.Check(body)
+	if result.Action == filter.ActionReject {
+		errs = append(errs, FieldError{
+			Field:   field,
+			Code:    "prohibited_words",
+			Message: "Chirp contains prohibited words",
+		})
+	}
+
+	if len(errs) > 0 {
+		return filter.Result{}, errs
+	}
+	return result, nil
+}
+
+// screenChirpBody runs body through validateChirpBody. Invalid bodies get a
+// 400 response listing the field errors and false; otherwise the caller
+// stores result.Body and passes the result to flagIfNeeded once the chirp
+// exists.
+func (cfg *apiConfig) screenChirpBody(w http.ResponseWriter, body, field string, maxLength int) (filter.Result, bool) {
+	result, errs := cfg.validateChirpBody(body, field, maxLength)
+	if len(errs) > 0 {
+		respondWithFieldErrors(w, errs)
+		return filter.Result{}, false
+	}
+	return result, true
+}
+
+// respondWithFieldErrors writes a 400 whose error message is the first
+// problem found, so clients that only read "error" keep working.
+func respondWithFieldErrors(w http.ResponseWriter, errs []FieldError) error {
+	type fieldErrorResponse struct {
+		Error  string       `json:"error"`
+		Fields []FieldError `json:"fields"`
+	}
+
+	return respondWithJSON(w, http.StatusBadRequest, fieldErrorResponse{
+		Error:  errs[0].Message,
+		Fields: errs,
+	})
+}
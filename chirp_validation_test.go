@@ -0,0 +1,62 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github/anansi-1/Chirpy/internal/filter"
+)
+
+func TestValidateChirpBody(t *testing.T) {
+	cfg := &apiConfig{
+		filter: filter.New([]filter.Rule{{Word: "kerfuffle", Action: filter.ActionReject}}),
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		codes []string
+	}{
+		{"plain text at the limit", strings.Repeat("a", 140), nil},
+		{"plain text over the limit", strings.Repeat("a", 141), []string{"too_long"}},
+		{"ZWJ emoji count once", strings.Repeat("\U0001F469\u200d\U0001F469\u200d\U0001F467\u200d\U0001F466", 140), nil},
+		{"combining accents count once", strings.Repeat("e\u0301", 140), nil},
+		{"141 accented graphemes", strings.Repeat("e\u0301", 141), []string{"too_long"}},
+		{"empty", "", []string{"required"}},
+		{"whitespace only", "   ", []string{"required"}},
+		{"prohibited word", "what a kerfuffle", []string{"prohibited_words"}},
+		{"too long and prohibited", "kerfuffle " + strings.Repeat("a", 140), []string{"too_long", "prohibited_words"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := cfg.validateChirpBody(tt.body, "body", 140)
+
+			var codes []string
+			for _, err := range errs {
+				if err.Field != "body" {
+					t.Errorf("Expected field %q, got %q", "body", err.Field)
+				}
+				codes = append(codes, err.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.codes) {
+				t.Errorf("Expected codes %v, got %v", tt.codes, codes)
+			}
+		})
+	}
+}
+
+func TestValidateChirpBody_ReturnsFilteredBody(t *testing.T) {
+	cfg := &apiConfig{
+		filter: filter.New([]filter.Rule{{Word: "kerfuffle", Action: filter.ActionReplace}}),
+	}
+
+	result, errs := cfg.validateChirpBody("what a kerfuffle", "body", 140)
+	if len(errs) > 0 {
+		t.Fatalf("validateChirpBody failed: %v", errs)
+	}
+	if result.Body != "what a "+filter.Mask {
+		t.Errorf("Expected masked body, got %q", result.Body)
+	}
+}
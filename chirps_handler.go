@@ -1,17 +1,229 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"github/anansi-1/Chirpy/internal/auth"
 	"github/anansi-1/Chirpy/internal/database"
+	"github/anansi-1/Chirpy/internal/filter"
 	"net/http"
-	"sort"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-func handleValidateChirp(w http.ResponseWriter, r *http.Request) {
+type ChirpResponse struct {
+	ID          string               `json:"id"`
+	CreatedAt   string               `json:"created_at"`
+	UpdatedAt   string               `json:"updated_at"`
+	Body        string               `json:"body"`
+	UserID      string               `json:"user_id"`
+	InReplyTo   *string              `json:"in_reply_to"`
+	ReplyCount  int32                `json:"reply_count"`
+	LikeCount   int32                `json:"like_count"`
+	LikedByMe   *bool                `json:"liked_by_me,omitempty"`
+	Kind        string               `json:"kind"`
+	Original    *OriginalChirp       `json:"original,omitempty"`
+	Attachments []AttachmentResponse `json:"attachments"`
+	PublishAt   *string              `json:"publish_at,omitempty"`
+	Visibility  string               `json:"visibility"`
+	DeletedAt   *string              `json:"deleted_at,omitempty"`
+}
+
+// validVisibilities lists who may read a chirp: everyone, the author's
+// followers, or only the author.
+var validVisibilities = map[string]bool{
+	"public":    true,
+	"followers": true,
+	"private":   true,
+}
+
+// OriginalChirp is the chirp a rechirp or quote chirp points at. Once the
+// original is deleted only its ID remains and Unavailable is set.
+type OriginalChirp struct {
+	ID          string         `json:"id"`
+	Unavailable bool           `json:"unavailable"`
+	Chirp       *ChirpResponse `json:"chirp"`
+}
+
+func newChirpResponse(chirp database.Chirp) ChirpResponse {
+	resp := ChirpResponse{
+		ID:          chirp.ID.String(),
+		CreatedAt:   chirp.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   chirp.UpdatedAt.Format(time.RFC3339),
+		Body:        chirp.Body,
+		UserID:      chirp.UserID.UUID.String(),
+		ReplyCount:  chirp.ReplyCount,
+		LikeCount:   chirp.LikeCount,
+		Kind:        chirp.Kind,
+		Attachments: []AttachmentResponse{},
+		Visibility:  chirp.Visibility,
+	}
+	if chirp.ParentID.Valid {
+		parentID := chirp.ParentID.UUID.String()
+		resp.InReplyTo = &parentID
+	}
+	if chirp.PublishAt.Valid {
+		publishAt := chirp.PublishAt.Time.Format(time.RFC3339)
+		resp.PublishAt = &publishAt
+	}
+	if chirp.DeletedAt.Valid {
+		deletedAt := chirp.DeletedAt.Time.Format(time.RFC3339)
+		resp.DeletedAt = &deletedAt
+	}
+	if chirp.OriginalChirpID.Valid {
+		resp.Original = &OriginalChirp{
+			ID:          chirp.OriginalChirpID.UUID.String(),
+			Unavailable: true,
+		}
+	}
+	return resp
+}
+
+// newChirpResponses converts a page of chirps, embeds the originals of any
+// rechirps or quotes, attaches their images and, when the request comes from a signed-in viewer,
+// marks the chirps they have liked. Each lookup is a single query for the
+// whole page.
+func (cfg *apiConfig) newChirpResponses(ctx context.Context, viewer uuid.NullUUID, chirpRows []database.Chirp) ([]ChirpResponse, error) {
+	chirps := make([]ChirpResponse, 0, len(chirpRows))
+	for _, chirp := range chirpRows {
+		chirps = append(chirps, newChirpResponse(chirp))
+	}
+
+	if err := cfg.embedOriginals(ctx, viewer, chirpRows, chirps); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.embedAttachments(ctx, chirps); err != nil {
+		return nil, err
+	}
+
+	if !viewer.Valid || len(chirpRows) == 0 {
+		return chirps, nil
+	}
+
+	chirpIDs := make([]uuid.UUID, 0, len(chirpRows))
+	for _, chirp := range chirpRows {
+		chirpIDs = append(chirpIDs, chirp.ID)
+	}
+
+	likedIDs, err := cfg.dbQueries.GetLikedChirpIDs(ctx, database.GetLikedChirpIDsParams{
+		UserID:   viewer.UUID,
+		ChirpIds: chirpIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	liked := make(map[uuid.UUID]bool, len(likedIDs))
+	for _, id := range likedIDs {
+		liked[id] = true
+	}
+
+	for i, chirp := range chirpRows {
+		likedByMe := liked[chirp.ID]
+		chirps[i].LikedByMe = &likedByMe
+	}
+
+	return chirps, nil
+}
+
+// embedOriginals fills in the original chirp of every rechirp and quote in
+// chirps. Originals that no longer exist or that viewer may not read stay
+// marked as unavailable.
+func (cfg *apiConfig) embedOriginals(ctx context.Context, viewer uuid.NullUUID, chirpRows []database.Chirp, chirps []ChirpResponse) error {
+	var originalIDs []uuid.UUID
+	for _, chirp := range chirpRows {
+		if chirp.OriginalChirpID.Valid {
+			originalIDs = append(originalIDs, chirp.OriginalChirpID.UUID)
+		}
+	}
+	if len(originalIDs) == 0 {
+		return nil
+	}
+
+	originalRows, err := cfg.dbQueries.GetChirpsByIDs(ctx, database.GetChirpsByIDsParams{
+		Ids:      originalIDs,
+		ViewerID: viewer,
+	})
+	if err != nil {
+		return err
+	}
+
+	originals := make(map[uuid.UUID]database.Chirp, len(originalRows))
+	for _, original := range originalRows {
+		originals[original.ID] = original
+	}
+
+	for i, chirp := range chirpRows {
+		original, ok := originals[chirp.OriginalChirpID.UUID]
+		if !chirp.OriginalChirpID.Valid || !ok {
+			continue
+		}
+		embedded := newChirpResponse(original)
+		chirps[i].Original = &OriginalChirp{
+			ID:    embedded.ID,
+			Chirp: &embedded,
+		}
+	}
+
+	return nil
+}
+
+// embedAttachments loads the images of every chirp in chirps, including
+// embedded originals, and adds them in upload order.
+func (cfg *apiConfig) embedAttachments(ctx context.Context, chirps []ChirpResponse) error {
+	byID := make(map[uuid.UUID][]*ChirpResponse)
+	var chirpIDs []uuid.UUID
+	add := func(chirp *ChirpResponse) {
+		id, err := uuid.Parse(chirp.ID)
+		if err != nil {
+			return
+		}
+		if _, ok := byID[id]; !ok {
+			chirpIDs = append(chirpIDs, id)
+		}
+		byID[id] = append(byID[id], chirp)
+	}
+	for i := range chirps {
+		add(&chirps[i])
+		if chirps[i].Original != nil && chirps[i].Original.Chirp != nil {
+			add(chirps[i].Original.Chirp)
+		}
+	}
+	if len(chirpIDs) == 0 {
+		return nil
+	}
+
+	attachments, err := cfg.dbQueries.GetAttachmentsByChirpIDs(ctx, chirpIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		for _, chirp := range byID[attachment.ChirpID] {
+			chirp.Attachments = append(chirp.Attachments, newAttachmentResponse(attachment))
+		}
+	}
+
+	return nil
+}
+
+// viewerID returns the caller's user ID when the route's auth middleware
+// resolved one. Endpoints that also serve anonymous readers use it to
+// personalise their responses.
+func (cfg *apiConfig) viewerID(r *http.Request) uuid.NullUUID {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok || !principal.HasScope(auth.ScopeChirpsRead) {
+		return uuid.NullUUID{}
+	}
+
+	return uuid.NullUUID{UUID: principal.UserID, Valid: true}
+}
+
+func (cfg *apiConfig) handleValidateChirp(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	var chirp struct {
@@ -23,127 +235,369 @@ func handleValidateChirp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(chirp.Body) > 140 {
-		respondWithError(w, http.StatusBadRequest, "Chirp is too long")
-		return
+	// Anonymous callers are checked against the free tier.
+	limits := cfg.tiers.Free
+	if viewer := cfg.viewerID(r); viewer.Valid {
+		var ok bool
+		if limits, ok = cfg.callerLimits(w, r, viewer.UUID); !ok {
+			return
+		}
 	}
 
-	res := cleanedBody(chirp.Body)
-	respondWithJSON(w, http.StatusOK, map[string]string{"cleaned_body": res})
+	result, ok := cfg.screenChirpBody(w, chirp.Body, "body", limits.MaxChirpLength)
+	if !ok {
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]string{"cleaned_body": result.Body})
 }
 
 func (cfg *apiConfig) handleCreateChirp(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	type ChirpRequest struct {
-		Body string `json:"body"`
+		Body       string     `json:"body"`
+		InReplyTo  string     `json:"in_reply_to"`
+		PublishAt  *time.Time `json:"publish_at"`
+		Visibility string     `json:"visibility"`
+	}
+
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+
+	var newChirp ChirpRequest
+	if err := json.NewDecoder(r.Body).Decode(&newChirp); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	limits, ok := cfg.callerLimits(w, r, userID)
+	if !ok {
+		return
+	}
+
+	screened, ok := cfg.screenChirpBody(w, newChirp.Body, "body", limits.MaxChirpLength)
+	if !ok {
+		return
+	}
+
+	var parentID uuid.NullUUID
+	if newChirp.InReplyTo != "" {
+		id, err := uuid.Parse(newChirp.InReplyTo)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid in_reply_to chirp ID")
+			return
+		}
+		_, err = cfg.dbQueries.GetChirpsByID(r.Context(), database.GetChirpsByIDParams{
+			ID:       id,
+			ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+		})
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Parent chirp not found")
+			return
+		}
+		parentID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	visibility := newChirp.Visibility
+	if visibility == "" {
+		visibility = "public"
+	}
+	if !validVisibilities[visibility] {
+		respondWithError(w, http.StatusBadRequest, "Visibility must be one of public, followers or private")
+		return
 	}
 
-	type ChirpResponse struct {
-		ID        string `json:"id"`
-		CreatedAt string `json:"created_at"`
-		UpdatedAt string `json:"updated_at"`
-		Body      string `json:"body"`
-		UserID    string `json:"user_id"`
+	var publishAt sql.NullTime
+	if newChirp.PublishAt != nil {
+		if !limits.ScheduledChirps {
+			respondWithError(w, http.StatusForbidden, "Scheduled chirps require Chirpy Red")
+			return
+		}
+		if !newChirp.PublishAt.After(time.Now()) {
+			respondWithError(w, http.StatusBadRequest, "publish_at must be in the future")
+			return
+		}
+		publishAt = sql.NullTime{Time: newChirp.PublishAt.UTC(), Valid: true}
 	}
 
-	tokenStr, err := auth.GetBearerToken(r.Header)
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Missing or invalid Authorization header")
+		respondWithError(w, http.StatusInternalServerError, "Error creating chirp")
 		return
 	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	// Scheduled chirps count against the hour they are created in. The
+	// check also locks the user's row, which keeps the scheduled count
+	// below accurate too.
+	if !cfg.checkChirpRate(w, r, qtx, userID, limits) {
+		return
+	}
+
+	if publishAt.Valid {
+		scheduled, err := qtx.CountScheduledChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error creating chirp")
+			return
+		}
+		if scheduled >= int64(limits.MaxScheduledChirps) {
+			respondWithError(w, http.StatusTooManyRequests, "Scheduled chirp limit reached")
+			return
+		}
+	}
 
-	userID, err := auth.ValidateJWT(tokenStr, cfg.tokenSecret)
+	chirp, err := qtx.CreateChirp(r.Context(), database.CreateChirpParams{
+		Body:       screened.Body,
+		UserID:     uuid.NullUUID{UUID: userID, Valid: true},
+		ParentID:   parentID,
+		PublishAt:  publishAt,
+		Visibility: visibility,
+	})
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Invalid or expired token")
+		respondWithError(w, http.StatusBadRequest, "Error creating chirp")
 		return
 	}
 
-	var newChirp ChirpRequest
-	if err := json.NewDecoder(r.Body).Decode(&newChirp); err != nil {
+	if err := flagIfNeeded(r.Context(), qtx, chirp.ID, screened); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error creating chirp")
+		return
+	}
+
+	if err := enqueueChirpCreated(r.Context(), qtx, chirp); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error creating chirp")
+		return
+	}
+
+	// A scheduled reply is counted when it is published.
+	if parentID.Valid && !publishAt.Valid {
+		if err := qtx.IncrementReplyCount(r.Context(), parentID.UUID); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error creating chirp")
+			return
+		}
+	}
+
+	if err := indexChirpEntities(r.Context(), qtx, chirp.ID, chirp.Body); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error creating chirp")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error creating chirp")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, newChirpResponse(chirp))
+}
+
+func (cfg *apiConfig) handleCreateRechirp(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	cfg.createRechirp(w, r, "rechirp", "")
+}
+
+func (cfg *apiConfig) handleCreateQuoteChirp(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	type QuoteRequest struct {
+		Body string `json:"body"`
+	}
+
+	var quote QuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&quote); err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
 		return
 	}
 
-	chirp, err := cfg.dbQueries.CreateChirp(r.Context(), database.CreateChirpParams{
-		Body:   newChirp.Body,
-		UserID: uuid.NullUUID{UUID: userID, Valid: true},
+	cfg.createRechirp(w, r, "quote", quote.Body)
+}
+
+// createRechirp re-shares the chirp named by the {chirpID} path value. A
+// plain rechirp of a rechirp points at the underlying original so that
+// chains never nest; quotes keep pointing at exactly what was quoted.
+// body is the quote text, empty for plain rechirps.
+func (cfg *apiConfig) createRechirp(w http.ResponseWriter, r *http.Request, kind, body string) {
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format")
+		return
+	}
+
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+
+	limits, ok := cfg.callerLimits(w, r, userID)
+	if !ok {
+		return
+	}
+
+	var screened filter.Result
+	if kind == "quote" {
+		if screened, ok = cfg.screenChirpBody(w, body, "body", limits.MaxChirpLength); !ok {
+			return
+		}
+	}
+
+	viewer := uuid.NullUUID{UUID: userID, Valid: true}
+
+	original, err := cfg.dbQueries.GetChirpsByID(r.Context(), database.GetChirpsByIDParams{
+		ID:       chirpUUID,
+		ViewerID: viewer,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp not found")
+		return
+	}
+
+	if original.Kind == "rechirp" {
+		original, err = cfg.dbQueries.GetChirpsByID(r.Context(), database.GetChirpsByIDParams{
+			ID:       original.OriginalChirpID.UUID,
+			ViewerID: viewer,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Original chirp is no longer available")
+			return
+		}
+	}
+
+	// Re-sharing would show the chirp to readers its author excluded.
+	if original.Visibility != "public" {
+		respondWithError(w, http.StatusForbidden, "Only public chirps can be rechirped or quoted")
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error creating chirp")
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	if !cfg.checkChirpRate(w, r, qtx, userID, limits) {
+		return
+	}
+
+	chirp, err := qtx.CreateRechirp(r.Context(), database.CreateRechirpParams{
+		Body:            screened.Body,
+		UserID:          uuid.NullUUID{UUID: userID, Valid: true},
+		Kind:            kind,
+		OriginalChirpID: uuid.NullUUID{UUID: original.ID, Valid: true},
 	})
 	if err != nil {
+		if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
+			respondWithError(w, http.StatusConflict, "Chirp already rechirped")
+			return
+		}
 		respondWithError(w, http.StatusBadRequest, "Error creating chirp")
 		return
 	}
 
-	resp := ChirpResponse{
-		ID:        chirp.ID.String(),
-		CreatedAt: chirp.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: chirp.UpdatedAt.Format(time.RFC3339),
-		Body:      chirp.Body,
-		UserID:    chirp.UserID.UUID.String(),
+	if err := flagIfNeeded(r.Context(), qtx, chirp.ID, screened); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error creating chirp")
+		return
+	}
+
+	if err := enqueueChirpCreated(r.Context(), qtx, chirp); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error creating chirp")
+		return
+	}
+
+	if err := indexChirpEntities(r.Context(), qtx, chirp.ID, chirp.Body); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error creating chirp")
+		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, resp)
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error creating chirp")
+		return
+	}
+
+	chirps, err := cfg.newChirpResponses(r.Context(), viewer, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error creating chirp")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, chirps[0])
 }
 
 func (cfg *apiConfig) handleGetChirps(w http.ResponseWriter, r *http.Request) {
 
-	type ChirpResponse struct {
-		ID        string `json:"id"`
-		CreatedAt string `json:"created_at"`
-		UpdatedAt string `json:"updated_at"`
-		Body      string `json:"body"`
-		UserID    string `json:"user_id"`
-	}
+	query := r.URL.Query()
 
-	s := r.URL.Query().Get("author_id")
-	sortOrder := r.URL.Query().Get("sort")
+	sortOrder := query.Get("sort")
 	if sortOrder != "desc" {
 		sortOrder = "asc"
 	}
 
-	var chirpRows []database.Chirp
-	var err error
+	limit, err := parsePageLimit(query.Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
 
-	if s != "" {
-		authorID, err := uuid.Parse(s)
+	var authorID uuid.NullUUID
+	if s := query.Get("author_id"); s != "" {
+		id, err := uuid.Parse(s)
 		if err != nil {
 			respondWithError(w, http.StatusBadRequest, "Invalid UUID format")
 			return
 		}
-		authorUUID := uuid.NullUUID{
-			UUID:  authorID,
-			Valid: true,
-		}
-		chirpRows, err = cfg.dbQueries.GetChirpsByAuthorID(r.Context(), authorUUID)
-		if err != nil {
-			respondWithError(w, http.StatusInternalServerError, "Error getting chirps by author")
-			return
-		}
+		authorID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	cursorCreatedAt, cursorID, err := cursorParams(query.Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+
+	viewer := cfg.viewerID(r)
+
+	// Fetch one extra row so we know whether another page exists.
+	var chirpRows []database.Chirp
+	if sortOrder == "desc" {
+		chirpRows, err = cfg.dbQueries.ListChirpsDesc(r.Context(), database.ListChirpsDescParams{
+			ViewerID:        viewer,
+			AuthorID:        authorID,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorID,
+			RowLimit:        int32(limit + 1),
+		})
 	} else {
-		chirpRows, err = cfg.dbQueries.GetAllChirps(r.Context())
-		if err != nil {
-			respondWithError(w, http.StatusInternalServerError, "Error getting chirps")
-			return
-		}
+		chirpRows, err = cfg.dbQueries.ListChirpsAsc(r.Context(), database.ListChirpsAscParams{
+			ViewerID:        viewer,
+			AuthorID:        authorID,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorID,
+			RowLimit:        int32(limit + 1),
+		})
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting chirps")
+		return
 	}
 
-	sort.Slice(chirpRows, func(i, j int) bool {
-		if sortOrder == "asc" {
-			return chirpRows[i].CreatedAt.Before(chirpRows[j].CreatedAt)
-		}
-		return chirpRows[i].CreatedAt.After(chirpRows[j].CreatedAt)
-	})
+	cfg.respondWithChirpPage(w, r, chirpRows, limit)
+}
 
-	var chirps []ChirpResponse
-	for _, chirp := range chirpRows {
-		resp := ChirpResponse{
-			ID:        chirp.ID.String(),
-			CreatedAt: chirp.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: chirp.UpdatedAt.Format(time.RFC3339),
-			Body:      chirp.Body,
-			UserID:    chirp.UserID.UUID.String(),
-		}
-		chirps = append(chirps, resp)
+// respondWithChirpPage writes one page of a keyset-paginated chirp feed.
+// chirpRows may hold one row more than limit, which is how the feed queries
+// signal that a next page exists.
+func (cfg *apiConfig) respondWithChirpPage(w http.ResponseWriter, r *http.Request, chirpRows []database.Chirp, limit int) {
+	if len(chirpRows) > limit {
+		chirpRows = chirpRows[:limit]
+		last := chirpRows[len(chirpRows)-1]
+		setNextLink(w, r, pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	chirps, err := cfg.newChirpResponses(r.Context(), cfg.viewerID(r), chirpRows)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting chirps")
+		return
 	}
 
 	respondWithJSON(w, http.StatusOK, chirps)
@@ -152,14 +606,6 @@ func (cfg *apiConfig) handleGetChirps(w http.ResponseWriter, r *http.Request) {
 func (cfg *apiConfig) handleGetChirpsByID(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
-	type ChirpResponse struct {
-		ID        string `json:"id"`
-		CreatedAt string `json:"created_at"`
-		UpdatedAt string `json:"updated_at"`
-		Body      string `json:"body"`
-		UserID    string `json:"user_id"`
-	}
-
 	chirpID := r.PathValue("chirpID")
 	chirpUUID, err := uuid.Parse(chirpID)
 	if err != nil {
@@ -167,61 +613,178 @@ func (cfg *apiConfig) handleGetChirpsByID(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	chirp, err := cfg.dbQueries.GetChirpsByID(r.Context(), chirpUUID)
+	// Chirps the viewer may not read are reported as missing rather than
+	// forbidden so their existence does not leak.
+	viewer := cfg.viewerID(r)
+	chirp, err := cfg.dbQueries.GetChirpsByID(r.Context(), database.GetChirpsByIDParams{
+		ID:       chirpUUID,
+		ViewerID: viewer,
+	})
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, "Chirp not found")
 		return
 	}
 
-	resp := ChirpResponse{
-		ID:        chirp.ID.String(),
-		CreatedAt: chirp.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: chirp.UpdatedAt.Format(time.RFC3339),
-		Body:      chirp.Body,
-		UserID:    chirp.UserID.UUID.String(),
+	chirps, err := cfg.newChirpResponses(r.Context(), viewer, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting chirp")
+		return
 	}
 
-	respondWithJSON(w, http.StatusOK, resp)
+	respondWithJSON(w, http.StatusOK, chirps[0])
 }
 
-func (cfg *apiConfig) handleDeleteChirp(w http.ResponseWriter, r *http.Request) {
-	defer r.Body.Close()
-
+// authorizeChirpOwner loads the chirp named by the {chirpID} path value and
+// checks that the caller is its author. The route must require
+// chirps:write. On failure it writes the error response itself and returns
+// false.
+func (cfg *apiConfig) authorizeChirpOwner(w http.ResponseWriter, r *http.Request) (database.Chirp, bool) {
 	chirpID := r.PathValue("chirpID")
 	chirpUUID, err := uuid.Parse(chirpID)
 	if err != nil {
-		http.Error(w, "Invalid chirp ID", http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID")
+		return database.Chirp{}, false
+	}
+
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+
+	chirp, err := cfg.dbQueries.GetChirpsByID(r.Context(), database.GetChirpsByIDParams{
+		ID:       chirpUUID,
+		ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp not found")
+		return database.Chirp{}, false
+	}
+
+	if !chirp.UserID.Valid || chirp.UserID.UUID != userID {
+		respondWithError(w, http.StatusForbidden, "You are not the owner of this chirp")
+		return database.Chirp{}, false
+	}
+
+	return chirp, true
+}
+
+func (cfg *apiConfig) handleDeleteChirp(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	chirp, ok := cfg.authorizeChirpOwner(w, r)
+	if !ok {
 		return
 	}
 
-	tokenStr, err := auth.GetBearerToken(r.Header)
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Missing or invalid Authorization header")
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete chirp")
 		return
 	}
+	defer tx.Rollback()
 
-	userID, err := auth.ValidateJWT(tokenStr, cfg.tokenSecret)
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	// Deleted chirps go to the owner's trash; purgeExpiredTrash removes
+	// them for good once the retention period is over.
+	trashed, err := qtx.TrashChirp(r.Context(), chirp.ID)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Invalid or expired token")
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete chirp")
+		return
+	}
+
+	if trashed == 1 && chirp.ParentID.Valid {
+		if err := qtx.DecrementReplyCount(r.Context(), chirp.ParentID.UUID); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to delete chirp")
+			return
+		}
+	}
+
+	if trashed == 1 {
+		if err := enqueueChirpDeleted(r.Context(), qtx, chirp); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to delete chirp")
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete chirp")
 		return
 	}
 
-	chirp, err := cfg.dbQueries.GetChirpsByID(r.Context(), chirpUUID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleUpdateChirp(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	type ChirpRequest struct {
+		Body string `json:"body"`
+	}
+
+	chirp, ok := cfg.authorizeChirpOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var update ChirpRequest
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	limits, ok := cfg.callerLimits(w, r, chirp.UserID.UUID)
+	if !ok {
+		return
+	}
+
+	screened, ok := cfg.screenChirpBody(w, update.Body, "body", limits.MaxChirpLength)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, "Chirp not found")
+		respondWithError(w, http.StatusInternalServerError, "Failed to update chirp")
 		return
 	}
+	defer tx.Rollback()
 
-	if !chirp.UserID.Valid || chirp.UserID.UUID.String() != userID.String() {
-		respondWithError(w, http.StatusForbidden, "You are not the owner of this chirp")
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	// Concurrent edits take turns here, so each revision records the body
+	// the previous edit left behind.
+	if err := qtx.LockChirp(r.Context(), chirp.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update chirp")
+		return
+	}
+
+	if err := qtx.CreateChirpRevision(r.Context(), chirp.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to save chirp revision")
 		return
 	}
 
-	err = cfg.dbQueries.DeleteChirpsByID(r.Context(), chirpUUID)
+	updated, err := qtx.UpdateChirpBody(r.Context(), database.UpdateChirpBodyParams{
+		ID:   chirp.ID,
+		Body: screened.Body,
+	})
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to delete chirp")
+		respondWithError(w, http.StatusInternalServerError, "Failed to update chirp")
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	if err := flagIfNeeded(r.Context(), qtx, updated.ID, screened); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update chirp")
+		return
+	}
+
+	if err := indexChirpEntities(r.Context(), qtx, updated.ID, updated.Body); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update chirp")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update chirp")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, newChirpResponse(updated))
 }
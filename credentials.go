@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github/anansi-1/Chirpy/internal/auth"
+	"github/anansi-1/Chirpy/internal/database"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// dbCredentialStore backs the authenticator with the sessions and
+// personal_access_tokens tables.
+type dbCredentialStore struct {
+	queries *database.Queries
+}
+
+func (s dbCredentialStore) SessionActive(ctx context.Context, userID, sessionID uuid.UUID) (bool, error) {
+	session, err := s.queries.GetSession(ctx, sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !session.RevokedAt.Valid && session.UserID == userID, nil
+}
+
+func (s dbCredentialStore) PersonalAccessToken(ctx context.Context, hash string) (auth.Principal, error) {
+	token, err := s.queries.GetPersonalAccessTokenByHash(ctx, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return auth.Principal{}, auth.ErrTokenNotFound
+	}
+	if err != nil {
+		return auth.Principal{}, err
+	}
+
+	// last_used_at is informational, so a failed update doesn't fail the
+	// request.
+	if err := s.queries.TouchPersonalAccessToken(ctx, token.ID); err != nil {
+		log.Printf("Error updating personal access token %s: %s", token.ID, err)
+	}
+
+	scopes := make([]auth.Scope, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, auth.Scope(scope))
+	}
+	return auth.Principal{UserID: token.UserID, TokenID: token.ID, Scopes: scopes}, nil
+}
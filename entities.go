@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github/anansi-1/Chirpy/internal/database"
+	"regexp"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/cases"
+)
+
+// A tag or mention must start the body or follow a character that cannot
+// be part of a word, so "a@b.com" and "issue#12" are not picked up.
+var (
+	hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_])#([\p{L}\p{N}_]{1,64})`)
+	mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_])@([\p{L}\p{N}_]{1,30})`)
+	handlePattern  = regexp.MustCompile(`^[\p{L}\p{N}_]{1,30}$`)
+)
+
+var caseFolder = cases.Fold()
+
+// extractHashtags returns the distinct, case-folded hashtags in body, in
+// the order they first appear. #Go and #go are the same tag.
+func extractHashtags(body string) []string {
+	return extractEntities(hashtagPattern, body)
+}
+
+// extractMentions returns the distinct, case-folded handles mentioned in
+// body. Whether they belong to real users is decided by the database.
+func extractMentions(body string) []string {
+	return extractEntities(mentionPattern, body)
+}
+
+func extractEntities(pattern *regexp.Regexp, body string) []string {
+	var entities []string
+	seen := make(map[string]bool)
+	for _, match := range pattern.FindAllStringSubmatch(body, -1) {
+		entity := caseFolder.String(match[1])
+		if seen[entity] {
+			continue
+		}
+		seen[entity] = true
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+// normalizeTag case-folds a tag taken from a URL, with or without its
+// leading #.
+func normalizeTag(tag string) string {
+	if len(tag) > 0 && tag[0] == '#' {
+		tag = tag[1:]
+	}
+	return caseFolder.String(tag)
+}
+
+// normalizeHandle validates a user handle and returns its case-folded form,
+// which is what gets stored and what mentions are matched against.
+func normalizeHandle(handle string) (string, error) {
+	if len(handle) > 0 && handle[0] == '@' {
+		handle = handle[1:]
+	}
+	if !handlePattern.MatchString(handle) {
+		return "", errors.New("handle must be 1-30 letters, digits or underscores")
+	}
+	return caseFolder.String(handle), nil
+}
+
+// indexChirpEntities replaces the hashtag and mention rows of a chirp with
+// the ones found in body. It runs inside the transaction that writes the
+// chirp so the side tables never disagree with the stored body.
+func indexChirpEntities(ctx context.Context, qtx *database.Queries, chirpID uuid.UUID, body string) error {
+	if err := qtx.DeleteChirpTags(ctx, chirpID); err != nil {
+		return err
+	}
+	if err := qtx.DeleteChirpMentions(ctx, chirpID); err != nil {
+		return err
+	}
+
+	if tags := extractHashtags(body); len(tags) > 0 {
+		if err := qtx.CreateTags(ctx, tags); err != nil {
+			return err
+		}
+		err := qtx.AddChirpTags(ctx, database.AddChirpTagsParams{
+			ChirpID: chirpID,
+			Names:   tags,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if handles := extractMentions(body); len(handles) > 0 {
+		err := qtx.AddChirpMentions(ctx, database.AddChirpMentionsParams{
+			ChirpID: chirpID,
+			Handles: handles,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
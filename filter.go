@@ -1,21 +1,69 @@
 package main
 
-import "strings"
+import (
+	"context"
+	"github/anansi-1/Chirpy/internal/database"
+	"github/anansi-1/Chirpy/internal/filter"
+	"log"
+	"time"
 
-func cleanedBody(s string) string {
+	"github.com/google/uuid"
+)
 
-	// wordsToBeRemoved := []string{"fornax","sharbert","kerfuffle"}
-	var cleanedWords []string
-	words := strings.Fields(s)
+// filterReloadInterval is how often every replica re-reads the word
+// lists, so edits made through another replica or to the word file are
+// picked up without a restart.
+const filterReloadInterval = time.Minute
+
+// dbFilterSource loads the filter words admins manage through the API.
+type dbFilterSource struct {
+	queries *database.Queries
+}
+
+func (s dbFilterSource) LoadRules(ctx context.Context) ([]filter.Rule, error) {
+	words, err := s.queries.ListFilterWords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]filter.Rule, 0, len(words))
 	for _, word := range words {
-		l := strings.ToLower(word)
-		if l == "fornax" || l == "sharbert" || l == "kerfuffle" {
-			cleanedWords = append(cleanedWords, "****")
-			continue
+		rules = append(rules, filter.Rule{Word: word.Word, Action: filter.Action(word.Action)})
+	}
+	return rules, nil
+}
+
+func (cfg *apiConfig) reloadFilter(ctx context.Context) error {
+	return cfg.filter.Reload(ctx, cfg.filterSources...)
+}
+
+// runFilterReloader reloads the filter every interval until ctx is
+// cancelled. It is started once from main.
+func (cfg *apiConfig) runFilterReloader(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := cfg.reloadFilter(ctx); err != nil {
+			log.Printf("Error reloading filter words: %s", err)
 		}
-		cleanedWords = append(cleanedWords, word)
 	}
+}
 
-	res := strings.Join(cleanedWords, " ")
-	return res
+// flagIfNeeded queues a chirp for review when its body matched a flag
+// rule. It runs inside the transaction that writes the chirp.
+func flagIfNeeded(ctx context.Context, qtx *database.Queries, chirpID uuid.UUID, result filter.Result) error {
+	if result.Action != filter.ActionFlag {
+		return nil
+	}
+	return qtx.FlagChirp(ctx, database.FlagChirpParams{
+		ChirpID:      chirpID,
+		MatchedWords: result.Matches,
+	})
 }
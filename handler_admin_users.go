@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github/anansi-1/Chirpy/internal/auth"
+	"github/anansi-1/Chirpy/internal/database"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AdminUserResponse struct {
+	ID          string  `json:"id"`
+	Email       string  `json:"email"`
+	Handle      string  `json:"handle,omitempty"`
+	Role        string  `json:"role"`
+	IsChirpyRed bool    `json:"is_chirpy_red"`
+	SuspendedAt *string `json:"suspended_at"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+}
+
+func newAdminUserResponse(user database.User) AdminUserResponse {
+	resp := AdminUserResponse{
+		ID:          user.ID.String(),
+		Email:       user.Email,
+		Handle:      user.Handle.String,
+		Role:        user.Role,
+		IsChirpyRed: user.IsChirpyRed.Bool,
+		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   user.UpdatedAt.Format(time.RFC3339),
+	}
+	if user.SuspendedAt.Valid {
+		suspendedAt := user.SuspendedAt.Time.Format(time.RFC3339)
+		resp.SuspendedAt = &suspendedAt
+	}
+	return resp
+}
+
+// handleGetUsers lists users newest first. q matches part of an email or
+// handle, and role and suspended narrow the list further.
+func (cfg *apiConfig) handleGetUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit, err := parsePageLimit(query.Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := cursorParams(query.Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+
+	var search sql.NullString
+	if q := strings.TrimSpace(query.Get("q")); q != "" {
+		search = sql.NullString{String: q, Valid: true}
+	}
+
+	var role sql.NullString
+	if s := query.Get("role"); s != "" {
+		if _, err := auth.ParseRole(s); err != nil {
+			respondWithError(w, http.StatusBadRequest, "role must be user, moderator or admin")
+			return
+		}
+		role = sql.NullString{String: s, Valid: true}
+	}
+
+	var suspended sql.NullBool
+	if s := query.Get("suspended"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "suspended must be true or false")
+			return
+		}
+		suspended = sql.NullBool{Bool: b, Valid: true}
+	}
+
+	users, err := cfg.dbQueries.ListUsers(r.Context(), database.ListUsersParams{
+		Query:           search,
+		Role:            role,
+		Suspended:       suspended,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		RowLimit:        int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting users")
+		return
+	}
+
+	if len(users) > limit {
+		users = users[:limit]
+		last := users[len(users)-1]
+		setNextLink(w, r, pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	resp := make([]AdminUserResponse, 0, len(users))
+	for _, user := range users {
+		resp = append(resp, newAdminUserResponse(user))
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// targetUser parses the {userID} path value, refusing the caller's own ID
+// so an admin cannot lock themselves out.
+func targetUser(w http.ResponseWriter, r *http.Request) (uuid.UUID, auth.Principal, bool) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID format")
+		return uuid.Nil, auth.Principal{}, false
+	}
+
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	if userID == principal.UserID {
+		respondWithError(w, http.StatusBadRequest, "You cannot change your own account here")
+		return uuid.Nil, auth.Principal{}, false
+	}
+	return userID, principal, true
+}
+
+// handleSuspendUser blocks a user from logging in and ends their sessions,
+// so their access tokens stop working at once. Their personal access
+// tokens are refused while the suspension lasts.
+func (cfg *apiConfig) handleSuspendUser(w http.ResponseWriter, r *http.Request) {
+	userID, principal, ok := targetUser(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := cfg.updateUserAccount(r.Context(), func(qtx *database.Queries) (database.User, error) {
+		user, err := qtx.SuspendUser(r.Context(), userID)
+		if err != nil {
+			return database.User{}, err
+		}
+		if err := revokeOtherSessions(r.Context(), qtx, userID, uuid.Nil); err != nil {
+			return database.User{}, err
+		}
+		return user, qtx.CreateSecurityEvent(r.Context(), database.CreateSecurityEventParams{
+			UserID: userID,
+			Event:  "account_suspended",
+			Detail: fmt.Sprintf("suspended by %s", principal.UserID),
+		})
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to suspend user")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, newAdminUserResponse(user))
+}
+
+func (cfg *apiConfig) handleUnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	userID, principal, ok := targetUser(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := cfg.updateUserAccount(r.Context(), func(qtx *database.Queries) (database.User, error) {
+		user, err := qtx.UnsuspendUser(r.Context(), userID)
+		if err != nil {
+			return database.User{}, err
+		}
+		return user, qtx.CreateSecurityEvent(r.Context(), database.CreateSecurityEventParams{
+			UserID: userID,
+			Event:  "account_unsuspended",
+			Detail: fmt.Sprintf("unsuspended by %s", principal.UserID),
+		})
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to unsuspend user")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, newAdminUserResponse(user))
+}
+
+// handleSetUserRole promotes or demotes a user. A promotion reaches the
+// user's access tokens on their next refresh; a demotion ends their
+// sessions so no token keeps the old role.
+func (cfg *apiConfig) handleSetUserRole(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	type SetRoleRequest struct {
+		Role string `json:"role"`
+	}
+
+	userID, principal, ok := targetUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req SetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	role, err := auth.ParseRole(req.Role)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "role must be user, moderator or admin")
+		return
+	}
+
+	user, err := cfg.updateUserAccount(r.Context(), func(qtx *database.Queries) (database.User, error) {
+		before, err := qtx.GetUserByID(r.Context(), userID)
+		if err != nil {
+			return database.User{}, err
+		}
+
+		user, err := qtx.SetUserRole(r.Context(), database.SetUserRoleParams{
+			Role: string(role),
+			ID:   userID,
+		})
+		if err != nil {
+			return database.User{}, err
+		}
+
+		if !role.Includes(auth.Role(before.Role)) {
+			if err := revokeOtherSessions(r.Context(), qtx, userID, uuid.Nil); err != nil {
+				return database.User{}, err
+			}
+		}
+
+		return user, qtx.CreateSecurityEvent(r.Context(), database.CreateSecurityEventParams{
+			UserID: userID,
+			Event:  "role_changed",
+			Detail: fmt.Sprintf("role changed from %s to %s by %s", before.Role, role, principal.UserID),
+		})
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to change role")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, newAdminUserResponse(user))
+}
+
+// updateUserAccount runs an admin change to a user account in one
+// transaction and returns the updated user.
+func (cfg *apiConfig) updateUserAccount(ctx context.Context, update func(qtx *database.Queries) (database.User, error)) (database.User, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.User{}, err
+	}
+	defer tx.Rollback()
+
+	user, err := update(cfg.dbQueries.WithTx(tx))
+	if err != nil {
+		return database.User{}, err
+	}
+	return user, tx.Commit()
+}
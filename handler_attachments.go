@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github/anansi-1/Chirpy/internal/blobstore"
+	"github/anansi-1/Chirpy/internal/database"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type AttachmentResponse struct {
+	ID          string `json:"id"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	Width       int32  `json:"width"`
+	Height      int32  `json:"height"`
+}
+
+func newAttachmentResponse(attachment database.Attachment) AttachmentResponse {
+	return AttachmentResponse{
+		ID:          attachment.ID.String(),
+		URL:         "/media/" + attachment.StorageKey,
+		ContentType: attachment.ContentType,
+		SizeBytes:   attachment.SizeBytes,
+		Width:       attachment.Width,
+		Height:      attachment.Height,
+	}
+}
+
+func (cfg *apiConfig) handleUploadAttachments(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	chirp, ok := cfg.authorizeChirpOwner(w, r)
+	if !ok {
+		return
+	}
+
+	limits, ok := cfg.callerLimits(w, r, chirp.UserID.UUID)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, int64(limits.MaxAttachmentsPerChirp)*maxAttachmentBytes+1<<20)
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid multipart upload")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	files := r.MultipartForm.File["files"]
+	if len(files) == 0 {
+		respondWithError(w, http.StatusBadRequest, "No files uploaded")
+		return
+	}
+
+	existing, err := cfg.dbQueries.CountChirpAttachments(r.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to upload attachments")
+		return
+	}
+	if int(existing)+len(files) > limits.MaxAttachmentsPerChirp {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("A chirp can have at most %d attachments", limits.MaxAttachmentsPerChirp))
+		return
+	}
+
+	// Validate every file before storing any of them.
+	images := make([]sanitizedImage, 0, len(files))
+	for _, fh := range files {
+		if fh.Size > maxAttachmentBytes {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Attachment is too large")
+			return
+		}
+
+		f, err := fh.Open()
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid multipart upload")
+			return
+		}
+		data, err := io.ReadAll(io.LimitReader(f, maxAttachmentBytes+1))
+		f.Close()
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid multipart upload")
+			return
+		}
+
+		img, err := sanitizeImage(data)
+		if errors.Is(err, errImageTooLarge) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Image dimensions are too large")
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusUnsupportedMediaType, "Attachments must be JPEG, PNG or GIF images")
+			return
+		}
+		images = append(images, img)
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to upload attachments")
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	// Blobs are written before their rows; if anything fails the ones
+	// already stored are removed again so no orphans are left behind.
+	var storedKeys []string
+	committed := false
+	defer func() {
+		if !committed {
+			cfg.deleteBlobs(storedKeys)
+		}
+	}()
+
+	attachments := make([]AttachmentResponse, 0, len(images))
+	for i, img := range images {
+		id := uuid.New()
+		key := id.String() + attachmentExtensions[img.ContentType]
+
+		if err := cfg.blobStore.Put(r.Context(), key, bytes.NewReader(img.Data), img.ContentType); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to store attachment")
+			return
+		}
+		storedKeys = append(storedKeys, key)
+
+		attachment, err := qtx.CreateAttachment(r.Context(), database.CreateAttachmentParams{
+			ID:          id,
+			ChirpID:     chirp.ID,
+			UserID:      chirp.UserID.UUID,
+			StorageKey:  key,
+			ContentType: img.ContentType,
+			SizeBytes:   int64(len(img.Data)),
+			Width:       int32(img.Width),
+			Height:      int32(img.Height),
+			Position:    int32(int(existing) + i),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to save attachment")
+			return
+		}
+		attachments = append(attachments, newAttachmentResponse(attachment))
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to save attachment")
+		return
+	}
+	committed = true
+
+	respondWithJSON(w, http.StatusCreated, attachments)
+}
+
+// deleteBlobs removes stored files whose attachment rows are gone. Failures
+// are only logged; the files are unreachable without their rows.
+func (cfg *apiConfig) deleteBlobs(keys []string) {
+	for _, key := range keys {
+		err := cfg.blobStore.Delete(context.Background(), key)
+		if err != nil && !errors.Is(err, blobstore.ErrNotFound) {
+			log.Printf("Error removing attachment %s: %s", key, err)
+		}
+	}
+}
+
+func (cfg *apiConfig) handleServeMedia(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+
+	attachment, err := cfg.dbQueries.GetAttachmentByStorageKey(r.Context(), key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Images follow the visibility of their chirp.
+	chirp, err := cfg.dbQueries.GetChirpsByID(r.Context(), database.GetChirpsByIDParams{
+		ID:       attachment.ChirpID,
+		ViewerID: cfg.viewerID(r),
+	})
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := cfg.blobStore.Get(r.Context(), attachment.StorageKey)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to read attachment", http.StatusInternalServerError)
+		return
+	}
+	defer body.Close()
+
+	// Keys are never reused, so the content behind a URL never changes.
+	// Only images of public chirps may sit in shared caches.
+	cacheScope := "private"
+	if chirp.Visibility == "public" {
+		cacheScope = "public"
+	}
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", cacheScope+", max-age=31536000, immutable")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, body)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"github/anansi-1/Chirpy/internal/database"
+	"github/anansi-1/Chirpy/internal/filter"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+type FilterWordResponse struct {
+	Word      string `json:"word"`
+	Action    string `json:"action"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func (cfg *apiConfig) handleGetFilterWords(w http.ResponseWriter, r *http.Request) {
+	words, err := cfg.dbQueries.ListFilterWords(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting filter words")
+		return
+	}
+
+	resp := make([]FilterWordResponse, 0, len(words))
+	for _, word := range words {
+		resp = append(resp, FilterWordResponse{
+			Word:      word.Word,
+			Action:    word.Action,
+			UpdatedAt: word.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handlePutFilterWord adds a word or changes its action. The word is stored
+// in normalised form and the filter on this replica is reloaded straight
+// away; other replicas pick the change up on their next reload.
+func (cfg *apiConfig) handlePutFilterWord(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	type FilterWordRequest struct {
+		Action string `json:"action"`
+	}
+
+	raw := r.PathValue("word")
+	word := filter.Normalize(raw)
+	if word == "" || strings.ContainsFunc(raw, unicode.IsSpace) {
+		respondWithError(w, http.StatusBadRequest, "Filter word must be a single word")
+		return
+	}
+
+	var req FilterWordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	action, err := filter.ParseAction(req.Action)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Action must be one of replace, flag or reject")
+		return
+	}
+
+	saved, err := cfg.dbQueries.UpsertFilterWord(r.Context(), database.UpsertFilterWordParams{
+		Word:   word,
+		Action: string(action),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to save filter word")
+		return
+	}
+
+	if err := cfg.reloadFilter(r.Context()); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Filter word saved but reload failed")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, FilterWordResponse{
+		Word:      saved.Word,
+		Action:    saved.Action,
+		UpdatedAt: saved.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+func (cfg *apiConfig) handleDeleteFilterWord(w http.ResponseWriter, r *http.Request) {
+	deleted, err := cfg.dbQueries.DeleteFilterWord(r.Context(), filter.Normalize(r.PathValue("word")))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete filter word")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Filter word not found")
+		return
+	}
+
+	if err := cfg.reloadFilter(r.Context()); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Filter word deleted but reload failed")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleGetFlaggedChirps(w http.ResponseWriter, r *http.Request) {
+	type FlaggedChirpResponse struct {
+		ChirpID      string   `json:"chirp_id"`
+		UserID       string   `json:"user_id"`
+		Body         string   `json:"body"`
+		MatchedWords []string `json:"matched_words"`
+		FlaggedAt    string   `json:"flagged_at"`
+	}
+
+	rows, err := cfg.dbQueries.ListFlaggedChirps(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting flagged chirps")
+		return
+	}
+
+	resp := make([]FlaggedChirpResponse, 0, len(rows))
+	for _, row := range rows {
+		resp = append(resp, FlaggedChirpResponse{
+			ChirpID:      row.ChirpID.String(),
+			UserID:       row.UserID.UUID.String(),
+			Body:         row.Body,
+			MatchedWords: row.MatchedWords,
+			FlaggedAt:    row.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleDismissChirpFlag clears a chirp from the review queue once a
+// moderator has looked at it.
+func (cfg *apiConfig) handleDismissChirpFlag(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format")
+		return
+	}
+
+	dismissed, err := cfg.dbQueries.DismissChirpFlag(r.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to dismiss flag")
+		return
+	}
+	if dismissed == 0 {
+		respondWithError(w, http.StatusNotFound, "Flagged chirp not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"github/anansi-1/Chirpy/internal/auth"
+	"github/anansi-1/Chirpy/internal/database"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FollowResponse struct {
+	UserID     string `json:"user_id"`
+	FollowedAt string `json:"followed_at"`
+}
+
+func (cfg *apiConfig) handleFollowUser(w http.ResponseWriter, r *http.Request) {
+	followeeID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+
+	if followeeID == userID {
+		respondWithError(w, http.StatusBadRequest, "You cannot follow yourself")
+		return
+	}
+
+	if _, err := cfg.dbQueries.GetUserByID(r.Context(), followeeID); err != nil {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	err = cfg.dbQueries.CreateFollow(r.Context(), database.CreateFollowParams{
+		FollowerID: userID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to follow user")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleUnfollowUser(w http.ResponseWriter, r *http.Request) {
+	followeeID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+
+	err = cfg.dbQueries.DeleteFollow(r.Context(), database.DeleteFollowParams{
+		FollowerID: userID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to unfollow user")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleGetFollowers(w http.ResponseWriter, r *http.Request) {
+	cfg.listFollows(w, r, true)
+}
+
+func (cfg *apiConfig) handleGetFollowing(w http.ResponseWriter, r *http.Request) {
+	cfg.listFollows(w, r, false)
+}
+
+// listFollows pages through either side of a user's follow graph, newest
+// relationship first.
+func (cfg *apiConfig) listFollows(w http.ResponseWriter, r *http.Request, followers bool) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit, err := parsePageLimit(query.Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := cursorParams(query.Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+
+	if _, err := cfg.dbQueries.GetUserByID(r.Context(), userID); err != nil {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	var follows []FollowResponse
+	var cursors []pageCursor
+	if followers {
+		rows, err := cfg.dbQueries.ListFollowers(r.Context(), database.ListFollowersParams{
+			UserID:          userID,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorID,
+			RowLimit:        int32(limit + 1),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error getting followers")
+			return
+		}
+		for _, row := range rows {
+			follows = append(follows, FollowResponse{
+				UserID:     row.UserID.String(),
+				FollowedAt: row.CreatedAt.Format(time.RFC3339),
+			})
+			cursors = append(cursors, pageCursor{CreatedAt: row.CreatedAt, ID: row.UserID})
+		}
+	} else {
+		rows, err := cfg.dbQueries.ListFollowing(r.Context(), database.ListFollowingParams{
+			UserID:          userID,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorID,
+			RowLimit:        int32(limit + 1),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error getting followed users")
+			return
+		}
+		for _, row := range rows {
+			follows = append(follows, FollowResponse{
+				UserID:     row.UserID.String(),
+				FollowedAt: row.CreatedAt.Format(time.RFC3339),
+			})
+			cursors = append(cursors, pageCursor{CreatedAt: row.CreatedAt, ID: row.UserID})
+		}
+	}
+
+	if len(follows) > limit {
+		follows = follows[:limit]
+		setNextLink(w, r, cursors[limit-1])
+	}
+
+	if follows == nil {
+		follows = []FollowResponse{}
+	}
+
+	respondWithJSON(w, http.StatusOK, follows)
+}
+
+func (cfg *apiConfig) handleGetTimeline(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+
+	query := r.URL.Query()
+
+	limit, err := parsePageLimit(query.Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := cursorParams(query.Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+
+	chirpRows, err := cfg.dbQueries.ListTimeline(r.Context(), database.ListTimelineParams{
+		FollowerID:      userID,
+		ViewerID:        uuid.NullUUID{UUID: userID, Valid: true},
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		RowLimit:        int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting timeline")
+		return
+	}
+
+	cfg.respondWithChirpPage(w, r, chirpRows, limit)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"github/anansi-1/Chirpy/internal/auth"
+	"github/anansi-1/Chirpy/internal/database"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handleLikeChirp(w http.ResponseWriter, r *http.Request) {
+	cfg.setChirpLike(w, r, true)
+}
+
+func (cfg *apiConfig) handleUnlikeChirp(w http.ResponseWriter, r *http.Request) {
+	cfg.setChirpLike(w, r, false)
+}
+
+// setChirpLike adds or removes the caller's like. Both directions are
+// idempotent: like_count only moves when a likes row is actually written
+// or removed, and both changes happen in one transaction.
+func (cfg *apiConfig) setChirpLike(w http.ResponseWriter, r *http.Request, like bool) {
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format")
+		return
+	}
+
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+
+	_, err = cfg.dbQueries.GetChirpsByID(r.Context(), database.GetChirpsByIDParams{
+		ID:       chirpUUID,
+		ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp not found")
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update like")
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	if like {
+		created, err := qtx.CreateLike(r.Context(), database.CreateLikeParams{
+			UserID:  userID,
+			ChirpID: chirpUUID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to like chirp")
+			return
+		}
+		if created > 0 {
+			if err := qtx.IncrementLikeCount(r.Context(), chirpUUID); err != nil {
+				respondWithError(w, http.StatusInternalServerError, "Failed to like chirp")
+				return
+			}
+		}
+	} else {
+		deleted, err := qtx.DeleteLike(r.Context(), database.DeleteLikeParams{
+			UserID:  userID,
+			ChirpID: chirpUUID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to unlike chirp")
+			return
+		}
+		if deleted > 0 {
+			if err := qtx.DecrementLikeCount(r.Context(), chirpUUID); err != nil {
+				respondWithError(w, http.StatusInternalServerError, "Failed to unlike chirp")
+				return
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update like")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
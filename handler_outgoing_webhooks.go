@@ -0,0 +1,240 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github/anansi-1/Chirpy/internal/auth"
+	"github/anansi-1/Chirpy/internal/database"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookEndpointResponse struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	Secret    string   `json:"secret,omitempty"`
+	CreatedAt string   `json:"created_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID            string                           `json:"id"`
+	EventID       string                           `json:"event_id"`
+	EndpointID    string                           `json:"endpoint_id"`
+	Event         string                           `json:"event"`
+	Status        string                           `json:"status"`
+	Attempts      int32                            `json:"attempts"`
+	NextAttemptAt *string                          `json:"next_attempt_at,omitempty"`
+	CreatedAt     string                           `json:"created_at"`
+	AttemptLog    []WebhookDeliveryAttemptResponse `json:"attempt_log,omitempty"`
+}
+
+type WebhookDeliveryAttemptResponse struct {
+	StatusCode *int32  `json:"status_code"`
+	Error      *string `json:"error"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+func newWebhookEndpointResponse(endpoint database.WebhookEndpoint) WebhookEndpointResponse {
+	return WebhookEndpointResponse{
+		ID:        endpoint.ID.String(),
+		URL:       endpoint.Url,
+		Events:    endpoint.Events,
+		CreatedAt: endpoint.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func newWebhookDeliveryResponse(delivery database.ListWebhookDeliveriesRow) WebhookDeliveryResponse {
+	resp := WebhookDeliveryResponse{
+		ID:         delivery.ID.String(),
+		EventID:    delivery.OutboxID.String(),
+		EndpointID: delivery.EndpointID.String(),
+		Event:      delivery.Event,
+		Status:     delivery.Status,
+		Attempts:   delivery.Attempts,
+		CreatedAt:  delivery.CreatedAt.Format(time.RFC3339),
+	}
+	if delivery.Status == "pending" {
+		nextAttemptAt := delivery.NextAttemptAt.Format(time.RFC3339)
+		resp.NextAttemptAt = &nextAttemptAt
+	}
+	return resp
+}
+
+// handleCreateWebhookEndpoint subscribes a URL to events. The signing
+// secret is only returned here, so the caller has to store it.
+func (cfg *apiConfig) handleCreateWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	type WebhookEndpointRequest struct {
+		URL    string   `json:"url"`
+		Events []string `json:"events"`
+	}
+
+	var req WebhookEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "https" && target.Scheme != "http") || target.Host == "" {
+		respondWithError(w, http.StatusBadRequest, "url must be an absolute http or https URL")
+		return
+	}
+
+	if len(req.Events) == 0 {
+		respondWithError(w, http.StatusBadRequest, "At least one event is required")
+		return
+	}
+	for _, event := range req.Events {
+		if !webhookEvents[event] {
+			respondWithError(w, http.StatusBadRequest, "Events must be chirp.created, chirp.deleted or user.upgraded")
+			return
+		}
+	}
+
+	secret, err := auth.MakeWebhookSecret()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create webhook")
+		return
+	}
+
+	endpoint, err := cfg.dbQueries.CreateWebhookEndpoint(r.Context(), database.CreateWebhookEndpointParams{
+		Url:    target.String(),
+		Secret: secret,
+		Events: req.Events,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create webhook")
+		return
+	}
+
+	resp := newWebhookEndpointResponse(endpoint)
+	resp.Secret = endpoint.Secret
+	respondWithJSON(w, http.StatusCreated, resp)
+}
+
+func (cfg *apiConfig) handleGetWebhookEndpoints(w http.ResponseWriter, r *http.Request) {
+	endpoints, err := cfg.dbQueries.ListWebhookEndpoints(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting webhooks")
+		return
+	}
+
+	resp := make([]WebhookEndpointResponse, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		resp = append(resp, newWebhookEndpointResponse(endpoint))
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handleDeleteWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	endpointID, err := uuid.Parse(r.PathValue("endpointID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid webhook ID format")
+		return
+	}
+
+	deleted, err := cfg.dbQueries.DeleteWebhookEndpoint(r.Context(), endpointID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete webhook")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Webhook not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetWebhookDeliveries lists the most recent deliveries to one
+// endpoint, newest first.
+func (cfg *apiConfig) handleGetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	endpointID, err := uuid.Parse(r.PathValue("endpointID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid webhook ID format")
+		return
+	}
+
+	deliveries, err := cfg.dbQueries.ListWebhookDeliveries(r.Context(), endpointID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting webhook deliveries")
+		return
+	}
+
+	resp := make([]WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		resp = append(resp, newWebhookDeliveryResponse(delivery))
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleGetWebhookDelivery returns one delivery with every attempt made
+// so far.
+func (cfg *apiConfig) handleGetWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	deliveryID, err := uuid.Parse(r.PathValue("deliveryID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid delivery ID format")
+		return
+	}
+
+	delivery, err := cfg.dbQueries.GetWebhookDelivery(r.Context(), deliveryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Delivery not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting webhook delivery")
+		return
+	}
+
+	attempts, err := cfg.dbQueries.ListWebhookDeliveryAttempts(r.Context(), deliveryID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting webhook delivery")
+		return
+	}
+
+	resp := newWebhookDeliveryResponse(database.ListWebhookDeliveriesRow(delivery))
+	resp.AttemptLog = make([]WebhookDeliveryAttemptResponse, 0, len(attempts))
+	for _, attempt := range attempts {
+		entry := WebhookDeliveryAttemptResponse{CreatedAt: attempt.CreatedAt.Format(time.RFC3339)}
+		if attempt.StatusCode.Valid {
+			entry.StatusCode = &attempt.StatusCode.Int32
+		}
+		if attempt.Error.Valid {
+			entry.Error = &attempt.Error.String
+		}
+		resp.AttemptLog = append(resp.AttemptLog, entry)
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleRedeliverWebhook queues a delivery to be sent again straight away,
+// with a fresh set of retries. Earlier attempts stay in its log.
+func (cfg *apiConfig) handleRedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	deliveryID, err := uuid.Parse(r.PathValue("deliveryID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid delivery ID format")
+		return
+	}
+
+	if _, err := cfg.dbQueries.RedeliverWebhook(r.Context(), deliveryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Delivery not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to redeliver webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
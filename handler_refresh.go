@@ -1,12 +1,51 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"github/anansi-1/Chirpy/internal/auth"
+	"github/anansi-1/Chirpy/internal/database"
+	"log"
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
 )
 
+// refreshTokenTTL is how long a refresh token stays usable if it is never
+// exchanged.
+const refreshTokenTTL = 60 * 24 * time.Hour
+
+// issueRefreshToken creates a refresh token in familyID. Logging in starts
+// a new family and every refresh continues it, so one family is one
+// sign-in.
+func issueRefreshToken(ctx context.Context, q *database.Queries, userID, familyID uuid.UUID) (string, error) {
+	token, err := auth.MakeRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = q.CreateRefreshToken(ctx, database.CreateRefreshTokenParams{
+		Token:     token,
+		UserID:    userID,
+		ExpiresAt: time.Now().UTC().Add(refreshTokenTTL),
+		FamilyID:  familyID,
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// handleRefreshAccessToken exchanges a refresh token for a new access token
+// and a new refresh token; the presented one is marked as replaced. A
+// replaced token presented again within 30 seconds gets the token that
+// replaced it, so clients that retry a refresh or refresh from two tabs at
+// once keep working. Later it means the token was copied and used by
+// someone else, so the whole family is revoked and both parties have to
+// log in again.
 func (cfg *apiConfig) handleRefreshAccessToken(w http.ResponseWriter, r *http.Request) {
 	refreshToken, err := auth.GetBearerToken(r.Header)
 	if err != nil {
@@ -20,22 +59,162 @@ func (cfg *apiConfig) handleRefreshAccessToken(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	if tokenRecord.ExpiresAt.Before(time.Now()) || tokenRecord.RevokedAt.Valid {
+	session, err := cfg.dbQueries.GetSession(r.Context(), tokenRecord.FamilyID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not refresh token")
+		return
+	}
+
+	// Tokens of a session the user ended are revoked too; presenting one
+	// is expected rather than a sign of theft.
+	if session.RevokedAt.Valid {
+		respondWithError(w, http.StatusUnauthorized, "Session has been revoked")
+		return
+	}
+
+	if tokenRecord.ReplacedBy.Valid {
+		replacement, err := cfg.dbQueries.GetRefreshTokenReplacement(r.Context(), refreshToken)
+		if errors.Is(err, sql.ErrNoRows) {
+			if err := cfg.revokeReusedRefreshToken(r.Context(), tokenRecord); err != nil {
+				log.Printf("Error revoking refresh token family %s: %s", tokenRecord.FamilyID, err)
+			}
+			respondWithError(w, http.StatusUnauthorized, "Refresh token expired or revoked")
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Could not refresh token")
+			return
+		}
+		cfg.respondWithRefreshedTokens(w, r, tokenRecord.UserID, session.ID, replacement.Token)
+		return
+	}
+
+	if tokenRecord.RevokedAt.Valid || tokenRecord.ExpiresAt.Before(time.Now()) {
 		respondWithError(w, http.StatusUnauthorized, "Refresh token expired or revoked")
 		return
 	}
 
-	newToken, err := auth.MakeJWT(tokenRecord.UserID, cfg.tokenSecret, time.Hour)
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not refresh token")
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	newRefreshToken, err := issueRefreshToken(r.Context(), qtx, tokenRecord.UserID, tokenRecord.FamilyID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not refresh token")
+		return
+	}
+
+	rotated, err := qtx.RotateRefreshToken(r.Context(), database.RotateRefreshTokenParams{
+		ReplacedBy: newRefreshToken,
+		Token:      refreshToken,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not refresh token")
+		return
+	}
+	if rotated == 0 {
+		// A concurrent refresh rotated this token first; answer as for a
+		// retry and hand out the token it was replaced by.
+		tx.Rollback()
+		replacement, err := cfg.dbQueries.GetRefreshTokenReplacement(r.Context(), refreshToken)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "Refresh token expired or revoked")
+			return
+		}
+		cfg.respondWithRefreshedTokens(w, r, tokenRecord.UserID, session.ID, replacement.Token)
+		return
+	}
+
+	if err := qtx.TouchSession(r.Context(), database.TouchSessionParams{
+		UserAgent: r.UserAgent(),
+		Ip:        clientIP(r),
+		ExpiresAt: time.Now().UTC().Add(refreshTokenTTL),
+		ID:        session.ID,
+	}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not refresh token")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not refresh token")
+		return
+	}
+
+	cfg.respondWithRefreshedTokens(w, r, tokenRecord.UserID, session.ID, newRefreshToken)
+}
+
+// respondWithRefreshedTokens sends refreshToken with a new access token for
+// the session. The user is read again so that a promotion shows up in the
+// access token and a suspended account gets none.
+func (cfg *apiConfig) respondWithRefreshedTokens(w http.ResponseWriter, r *http.Request, userID, sessionID uuid.UUID, refreshToken string) {
+	user, err := cfg.dbQueries.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not refresh token")
+		return
+	}
+	if user.SuspendedAt.Valid {
+		respondWithError(w, http.StatusForbidden, "Account suspended")
+		return
+	}
+
+	token, err := cfg.keyring.MakeAccessToken(auth.Principal{
+		UserID:    user.ID,
+		SessionID: sessionID,
+		Role:      auth.Role(user.Role),
+	}, time.Hour)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Could not create new token")
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, map[string]string{
-		"token": newToken,
+		"token":         token,
+		"refresh_token": refreshToken,
 	})
 }
 
+// revokeReusedRefreshToken ends the session of a token that was presented
+// again after its grace period, and records a security event.
+func (cfg *apiConfig) revokeReusedRefreshToken(ctx context.Context, token database.RefreshToken) error {
+	log.Printf("Refresh token reuse detected for user %s; revoking family %s", token.UserID, token.FamilyID)
+
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	if err := qtx.RevokeRefreshTokenFamily(ctx, token.FamilyID); err != nil {
+		return err
+	}
+
+	if _, err := qtx.RevokeSession(ctx, database.RevokeSessionParams{
+		ID:     token.FamilyID,
+		UserID: token.UserID,
+	}); err != nil {
+		return err
+	}
+
+	if err := qtx.CreateSecurityEvent(ctx, database.CreateSecurityEventParams{
+		UserID: token.UserID,
+		Event:  "refresh_token_reuse",
+		Detail: fmt.Sprintf("replaced refresh token presented again; family %s revoked", token.FamilyID),
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// handleRevokeRefreshToken logs out: it ends the session the refresh token
+// belongs to, which also revokes that session's access tokens.
 func (cfg *apiConfig) handleRevokeRefreshToken(w http.ResponseWriter, r *http.Request) {
 
 	refreshToken, err := auth.GetBearerToken(r.Header)
@@ -44,12 +223,25 @@ func (cfg *apiConfig) handleRevokeRefreshToken(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	err = cfg.dbQueries.RevokeRefreshToken(r.Context(), refreshToken)
-
+	tokenRecord, err := cfg.dbQueries.GetRefreshToken(r.Context(), refreshToken)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Invalid or already revoked token")
 		return
 	}
 
+	// A client that logs out while a refresh is in flight may still hold
+	// the token that refresh replaced.
+	if tokenRecord.RevokedAt.Valid {
+		if _, err := cfg.dbQueries.GetRefreshTokenReplacement(r.Context(), refreshToken); err != nil {
+			respondWithError(w, http.StatusUnauthorized, "Invalid or already revoked token")
+			return
+		}
+	}
+
+	if _, err := cfg.revokeSession(r.Context(), tokenRecord.FamilyID, tokenRecord.UserID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to revoke token")
+		return
+	}
+
 	w.WriteHeader(http.StatusNoContent)
-}
\ No newline at end of file
+}
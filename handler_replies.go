@@ -0,0 +1,115 @@
+package main
+
+import (
+	"github/anansi-1/Chirpy/internal/database"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultThreadDepth = 5
+	maxThreadDepth     = 20
+)
+
+// ThreadNode is one chirp in a conversation tree. Chirp is nil when the
+// chirp was deleted after being replied to, or is sitting in its author's
+// trash; the node then only keeps its place in the tree.
+type ThreadNode struct {
+	ID      string         `json:"id"`
+	Deleted bool           `json:"deleted"`
+	Chirp   *ChirpResponse `json:"chirp"`
+	Replies []*ThreadNode  `json:"replies"`
+}
+
+func (cfg *apiConfig) handleGetChirpReplies(w http.ResponseWriter, r *http.Request) {
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format")
+		return
+	}
+
+	depth := defaultThreadDepth
+	if s := r.URL.Query().Get("depth"); s != "" {
+		depth, err = strconv.Atoi(s)
+		if err != nil || depth < 1 {
+			respondWithError(w, http.StatusBadRequest, "depth must be a positive integer")
+			return
+		}
+		depth = min(depth, maxThreadDepth)
+	}
+
+	// Replies the viewer may not read are left out together with
+	// everything below them.
+	viewer := cfg.viewerID(r)
+	rows, err := cfg.dbQueries.GetChirpThread(r.Context(), database.GetChirpThreadParams{
+		RootID:   chirpUUID,
+		ViewerID: viewer,
+		MaxDepth: int32(depth),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting replies")
+		return
+	}
+
+	if len(rows) == 0 {
+		respondWithError(w, http.StatusNotFound, "Chirp not found")
+		return
+	}
+
+	var chirpRows []database.Chirp
+	for _, row := range rows {
+		if row.IsTombstone || row.DeletedAt.Valid {
+			continue
+		}
+		chirpRows = append(chirpRows, database.Chirp{
+			ID:              row.ID,
+			CreatedAt:       row.CreatedAt,
+			UpdatedAt:       row.UpdatedAt,
+			Body:            row.Body,
+			UserID:          row.UserID,
+			ParentID:        row.ParentID,
+			ReplyCount:      row.ReplyCount,
+			LikeCount:       row.LikeCount,
+			Kind:            row.Kind,
+			OriginalChirpID: row.OriginalChirpID,
+			PublishAt:       row.PublishAt,
+			Visibility:      row.Visibility,
+		})
+	}
+
+	chirps, err := cfg.newChirpResponses(r.Context(), viewer, chirpRows)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting replies")
+		return
+	}
+
+	responses := make(map[uuid.UUID]*ChirpResponse, len(chirps))
+	for i, chirp := range chirpRows {
+		responses[chirp.ID] = &chirps[i]
+	}
+
+	// Rows arrive ordered by depth, so every parent is seen before its replies.
+	nodes := make(map[uuid.UUID]*ThreadNode, len(rows))
+	var root *ThreadNode
+	for _, row := range rows {
+		node := &ThreadNode{
+			ID:      row.ID.String(),
+			Deleted: row.IsTombstone || row.DeletedAt.Valid,
+			Chirp:   responses[row.ID],
+			Replies: []*ThreadNode{},
+		}
+		nodes[row.ID] = node
+
+		if row.Depth == 0 {
+			root = node
+			continue
+		}
+		if parent, ok := nodes[row.ParentID.UUID]; ok {
+			parent.Replies = append(parent.Replies, node)
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, root)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"github/anansi-1/Chirpy/internal/database"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handleGetChirpRevisions(w http.ResponseWriter, r *http.Request) {
+	type RevisionResponse struct {
+		ID         string `json:"id"`
+		ChirpID    string `json:"chirp_id"`
+		Body       string `json:"body"`
+		CreatedAt  string `json:"created_at"`
+		ReplacedAt string `json:"replaced_at"`
+	}
+
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format")
+		return
+	}
+
+	_, err = cfg.dbQueries.GetChirpsByID(r.Context(), database.GetChirpsByIDParams{
+		ID:       chirpUUID,
+		ViewerID: cfg.viewerID(r),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp not found")
+		return
+	}
+
+	revisionRows, err := cfg.dbQueries.GetChirpRevisions(r.Context(), chirpUUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting chirp revisions")
+		return
+	}
+
+	revisions := make([]RevisionResponse, 0, len(revisionRows))
+	for _, rev := range revisionRows {
+		revisions = append(revisions, RevisionResponse{
+			ID:         rev.ID.String(),
+			ChirpID:    rev.ChirpID.String(),
+			Body:       rev.Body,
+			CreatedAt:  rev.CreatedAt.Format(time.RFC3339),
+			ReplacedAt: rev.ReplacedAt.Format(time.RFC3339),
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, revisions)
+}
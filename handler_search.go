@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"github/anansi-1/Chirpy/internal/database"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handleSearchChirps(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	tsQuery, err := buildSearchQuery(query.Get("q"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Search query is required")
+		return
+	}
+
+	limit, err := parsePageLimit(query.Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	var authorID uuid.NullUUID
+	if s := query.Get("author_id"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid UUID format")
+			return
+		}
+		authorID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	viewer := cfg.viewerID(r)
+
+	chirpRows, err := cfg.dbQueries.SearchChirps(r.Context(), database.SearchChirpsParams{
+		Query:    tsQuery,
+		ViewerID: viewer,
+		AuthorID: authorID,
+		RowLimit: int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error searching chirps")
+		return
+	}
+
+	chirps, err := cfg.newChirpResponses(r.Context(), viewer, chirpRows)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error searching chirps")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chirps)
+}
+
+// buildSearchQuery turns user input into a to_tsquery expression.
+// "quoted words" become a phrase, a trailing * turns a word into a prefix
+// match, and all remaining terms must match. Anything that is not a letter
+// or digit is dropped, so the result is always a well-formed tsquery.
+func buildSearchQuery(q string) (string, error) {
+	var terms []string
+
+	rest := strings.TrimSpace(q)
+	for rest != "" {
+		var term string
+		if rest[0] == '"' {
+			phrase, after, _ := strings.Cut(rest[1:], `"`)
+			term = phraseQuery(phrase, false)
+			rest = after
+		} else {
+			word, after, _ := strings.Cut(rest, " ")
+			term = phraseQuery(word, strings.HasSuffix(word, "*"))
+			rest = after
+		}
+
+		if term != "" {
+			terms = append(terms, term)
+		}
+		rest = strings.TrimSpace(rest)
+	}
+
+	if len(terms) == 0 {
+		return "", errors.New("empty search query")
+	}
+
+	return strings.Join(terms, " & "), nil
+}
+
+// phraseQuery joins the lexemes of s with the followed-by operator. When
+// prefix is set the final lexeme matches any word starting with it.
+func phraseQuery(s string, prefix bool) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+
+	if prefix {
+		words[len(words)-1] += ":*"
+	}
+
+	if len(words) == 1 {
+		return words[0]
+	}
+	return "(" + strings.Join(words, " <-> ") + ")"
+}
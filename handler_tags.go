@@ -0,0 +1,85 @@
+package main
+
+import (
+	"github/anansi-1/Chirpy/internal/database"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handleGetTagChirps(w http.ResponseWriter, r *http.Request) {
+	tag := normalizeTag(r.PathValue("tag"))
+	if tag == "" {
+		respondWithError(w, http.StatusBadRequest, "Tag is required")
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit, err := parsePageLimit(query.Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := cursorParams(query.Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+
+	chirpRows, err := cfg.dbQueries.ListChirpsByTag(r.Context(), database.ListChirpsByTagParams{
+		Tag:             tag,
+		ViewerID:        cfg.viewerID(r),
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		RowLimit:        int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting chirps for tag")
+		return
+	}
+
+	cfg.respondWithChirpPage(w, r, chirpRows, limit)
+}
+
+func (cfg *apiConfig) handleGetUserMentions(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit, err := parsePageLimit(query.Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := cursorParams(query.Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+
+	if _, err := cfg.dbQueries.GetUserByID(r.Context(), userID); err != nil {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	chirpRows, err := cfg.dbQueries.ListChirpsMentioningUser(r.Context(), database.ListChirpsMentioningUserParams{
+		MentionedUserID: userID,
+		ViewerID:        cfg.viewerID(r),
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		RowLimit:        int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting mentions")
+		return
+	}
+
+	cfg.respondWithChirpPage(w, r, chirpRows, limit)
+}
@@ -2,61 +2,142 @@ package main
 
 
 import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"github/anansi-1/Chirpy/internal/auth"
+	"io"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-func (cfg *apiConfig) handleUpgradeWebhook(w http.ResponseWriter, r *http.Request) {
+const (
+	maxWebhookBytes = 1 << 20
+
+	// webhookTolerance is how far a delivery's signed timestamp may be from
+	// now. Older deliveries are refused, which bounds how long processed
+	// event IDs matter for replay protection.
+	webhookTolerance = 5 * time.Minute
+
+	// processedWebhookEventRetention is how long a processed event ID is
+	// kept. A delivery is accepted up to webhookTolerance either side of
+	// its timestamp, so a replay can arrive at most twice the tolerance
+	// after the original was processed.
+	processedWebhookEventRetention = 2 * webhookTolerance
+
+	processedWebhookEventPruneInterval = 10 * time.Minute
+)
+
+// handlePolkaWebhook applies subscription events sent by Polka. Events it
+// does not know about are acknowledged and ignored so Polka stops retrying
+// them.
+//
+// With POLKA_WEBHOOK_SECRET set, deliveries must carry an HMAC signature
+// over the timestamp and raw body plus an event id; an id that has been
+// processed before is acknowledged without being applied again. Without a
+// secret the older ApiKey header is accepted instead.
+func (cfg *apiConfig) handlePolkaWebhook(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
-	apiKey, err := auth.GetAPIKey(r.Header)
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBytes))
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Missing or invalid Authorization header")
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	if apiKey != cfg.apiKey {
-		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
-		return
+	if cfg.webhookSecret != "" {
+		if err := auth.ValidateWebhookSignature(r.Header, body, cfg.webhookSecret, webhookTolerance); err != nil {
+			respondWithError(w, http.StatusUnauthorized, "Invalid webhook signature")
+			return
+		}
+	} else {
+		apiKey, err := auth.GetAPIKey(r.Header)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "Missing or invalid Authorization header")
+			return
+		}
+
+		if cfg.apiKey == "" || subtle.ConstantTimeCompare([]byte(apiKey), []byte(cfg.apiKey)) != 1 {
+			respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
 	}
 
-	type UpgradeRequest struct {
+	type WebhookRequest struct {
+		ID    string `json:"id"`
 		Event string `json:"event"`
 		Data  struct {
-			UserID string `json:"user_id"`
+			UserID    string     `json:"user_id"`
+			ExpiresAt *time.Time `json:"expires_at"`
 		} `json:"data"`
 	}
 
-	var upgradeBody UpgradeRequest
-	if err := json.NewDecoder(r.Body).Decode(&upgradeBody); err != nil {
+	var webhook WebhookRequest
+	if err := json.Unmarshal(body, &webhook); err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
 		return
 	}
 
-	if upgradeBody.Event != "user.upgraded" {
+	if cfg.webhookSecret != "" && webhook.ID == "" {
+		respondWithError(w, http.StatusBadRequest, "Webhook event id is required")
+		return
+	}
+
+	if !subscriptionEvents[webhook.Event] {
 		respondWithJSON(w, http.StatusNoContent, nil)
 		return
 	}
 
-	userID, err := uuid.Parse(upgradeBody.Data.UserID)
+	userID, err := uuid.Parse(webhook.Data.UserID)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid UUID format")
 		return
 	}
 
-	rowsAffected, err := cfg.dbQueries.UpgradeUser(r.Context(), userID)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to upgrade user")
+	var expiresAt sql.NullTime
+	if webhook.Data.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: webhook.Data.ExpiresAt.UTC(), Valid: true}
+	}
+	if webhook.Event == "subscription.renewed" && !expiresAt.Valid {
+		respondWithError(w, http.StatusBadRequest, "Renewals must include expires_at")
 		return
 	}
 
-	if rowsAffected == 0 {
+	err = cfg.applySubscriptionEvent(r.Context(), webhook.ID, userID, webhook.Event, expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
 		respondWithError(w, http.StatusNotFound, "User not found")
 		return
 	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update subscription")
+		return
+	}
 
 	respondWithJSON(w, http.StatusNoContent, nil)
-}
\ No newline at end of file
+}
+
+// runProcessedWebhookEventPruner deletes processed event IDs once they can
+// no longer be replayed, every processedWebhookEventPruneInterval until ctx
+// is cancelled. It is started once from main.
+func (cfg *apiConfig) runProcessedWebhookEventPruner(ctx context.Context) {
+	ticker := time.NewTicker(processedWebhookEventPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		cutoff := time.Now().UTC().Add(-processedWebhookEventRetention)
+		if _, err := cfg.dbQueries.DeleteOldProcessedWebhookEvents(ctx, cutoff); err != nil {
+			log.Printf("Error deleting processed webhook events: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -32,52 +32,26 @@ func CheckPasswordHash(password, hash string) error{
 
 
 
-func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn  time.Duration)(string,error){
-
-	now := time.Now().UTC()
-	claims := jwt.RegisteredClaims{
-		Issuer: "chirpy",
-		Subject: userID.String(),
-		IssuedAt: jwt.NewNumericDate(now),
-		ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
-	}
-	
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256,claims)
-
-	tokenString,err := token.SignedString([]byte(tokenSecret))
-
-	if err != nil{
-		return "",err
-	}
-
-	return tokenString, nil
+// accessClaims are the claims of an access token. SessionID ties the
+// token to the login that issued it so that revoking the session revokes
+// the token too; tokens made with MakeJWT have none. Role is the user's
+// role when the token was issued.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	SessionID string `json:"sid,omitempty"`
+	Role      string `json:"role,omitempty"`
 }
 
-func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error){
-
-		claims := &jwt.RegisteredClaims{}
-
-		token,err :=  jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (any,error){
-
-			if t.Method != jwt.SigningMethodHS256{
-				return nil, errors.New("unexpected signing method")
-			}
-			return []byte(tokenSecret),nil
-
-		})
-
-		if err != nil {
-			return uuid.Nil,err
-		}
-
-		if !token.Valid{
-			return uuid.Nil,errors.New("invalid token")
-		}
-
-		userID, err := uuid.Parse(claims.Subject)
-
-		return userID,nil
+func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (string, error) {
+	return NewHMACKeyring(tokenSecret).MakeAccessToken(Principal{UserID: userID}, expiresIn)
+}
 
+func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
+	p, err := NewHMACKeyring(tokenSecret).ParseAccessToken(tokenString)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return p.UserID, nil
 }
 
 func GetBearerToken(headers http.Header) (string, error) {
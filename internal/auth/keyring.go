@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// SigningKey is one key in a Keyring. HMAC keys sign and verify with the
+// same secret and are never published; Ed25519 and RSA keys publish their
+// public half in the JWKS.
+type SigningKey struct {
+	ID     string
+	method jwt.SigningMethod
+	sign   any
+	verify any
+}
+
+func NewHMACKey(id string, secret []byte) SigningKey {
+	return SigningKey{ID: id, method: jwt.SigningMethodHS256, sign: secret, verify: secret}
+}
+
+func NewEd25519Key(id string, key ed25519.PrivateKey) SigningKey {
+	return SigningKey{ID: id, method: jwt.SigningMethodEdDSA, sign: key, verify: key.Public()}
+}
+
+func NewRSAKey(id string, key *rsa.PrivateKey) SigningKey {
+	return SigningKey{ID: id, method: jwt.SigningMethodRS256, sign: key, verify: &key.PublicKey}
+}
+
+// ParsePrivateKeyPEM reads an Ed25519 or RSA private key in PKCS#8 or, for
+// RSA, PKCS#1 PEM form.
+func ParsePrivateKeyPEM(id string, data []byte) (SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return SigningKey{}, errors.New("no PEM block found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return NewRSAKey(id, key), nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return SigningKey{}, err
+	}
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		return NewEd25519Key(id, key), nil
+	case *rsa.PrivateKey:
+		return NewRSAKey(id, key), nil
+	default:
+		return SigningKey{}, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+// Keyring signs access tokens with its current key and verifies them with
+// whichever key their kid header names, so a new key can be rolled in
+// while tokens signed with an older one stay valid until they expire.
+type Keyring struct {
+	current SigningKey
+	keys    map[string]SigningKey
+
+	// legacy verifies tokens with no kid header, which were all signed
+	// with the HMAC secret before keys had IDs.
+	legacy *SigningKey
+}
+
+// NewKeyring returns a keyring that signs with current and also accepts
+// tokens signed by any of older.
+func NewKeyring(current SigningKey, older ...SigningKey) (*Keyring, error) {
+	k := &Keyring{current: current, keys: map[string]SigningKey{}}
+	for _, key := range append([]SigningKey{current}, older...) {
+		if key.ID == "" {
+			return nil, errors.New("signing key has no ID")
+		}
+		if _, ok := k.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate signing key ID %q", key.ID)
+		}
+		k.keys[key.ID] = key
+		if key.method == jwt.SigningMethodHS256 && k.legacy == nil {
+			legacy := key
+			k.legacy = &legacy
+		}
+	}
+	return k, nil
+}
+
+// NewHMACKeyring is a keyring holding just one HMAC secret.
+func NewHMACKeyring(secret string) *Keyring {
+	k, _ := NewKeyring(NewHMACKey("default", []byte(secret)))
+	return k
+}
+
+// MakeAccessToken makes an access token for p's user, session and role.
+// Scopes are not stored; every login token has all of them.
+func (k *Keyring) MakeAccessToken(p Principal, expiresIn time.Duration) (string, error) {
+	now := time.Now().UTC()
+	claims := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "chirpy",
+			Subject:   p.UserID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+		},
+		Role: string(p.Role),
+	}
+	if p.SessionID != uuid.Nil {
+		claims.SessionID = p.SessionID.String()
+	}
+
+	token := jwt.NewWithClaims(k.current.method, claims)
+	token.Header["kid"] = k.current.ID
+	return token.SignedString(k.current.sign)
+}
+
+// ParseAccessToken checks an access token and returns the user, session
+// and role it was issued for. Tokens from before roles existed have the
+// user role.
+func (k *Keyring) ParseAccessToken(tokenString string) (Principal, error) {
+	claims := &accessClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, k.keyFunc)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	if !token.Valid {
+		return Principal{}, errors.New("invalid token")
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	sessionID := uuid.Nil
+	if claims.SessionID != "" {
+		sessionID, err = uuid.Parse(claims.SessionID)
+		if err != nil {
+			return Principal{}, err
+		}
+	}
+
+	role := RoleUser
+	if claims.Role != "" {
+		role, err = ParseRole(claims.Role)
+		if err != nil {
+			return Principal{}, err
+		}
+	}
+
+	return Principal{UserID: userID, SessionID: sessionID, Role: role}, nil
+}
+
+// keyFunc picks the verification key by kid. The token's alg must match
+// the key's, so a public key can never be used as an HMAC secret.
+func (k *Keyring) keyFunc(t *jwt.Token) (any, error) {
+	var key SigningKey
+	switch kid, ok := t.Header["kid"].(string); {
+	case ok:
+		found, exists := k.keys[kid]
+		if !exists {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		key = found
+	case k.legacy != nil:
+		key = *k.legacy
+	default:
+		return nil, errors.New("token has no kid header")
+	}
+
+	if t.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.verify, nil
+}
+
+// JWK is a public key in JSON Web Key form.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of every asymmetric key in the keyring.
+func (k *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range k.ordered() {
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.method.Alg()}
+		switch pub := key.verify.(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// ordered lists the current key first and the rest by ID, so the JWKS is
+// stable between requests.
+func (k *Keyring) ordered() []SigningKey {
+	keys := []SigningKey{k.current}
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		if id != k.current.ID {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		keys = append(keys, k.keys[id])
+	}
+	return keys
+}
@@ -0,0 +1,133 @@
+package auth_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github/anansi-1/Chirpy/internal/auth"
+
+	"github.com/google/uuid"
+)
+
+func newEd25519Key(t *testing.T, id string) auth.SigningKey {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	return auth.NewEd25519Key(id, priv)
+}
+
+func TestKeyring_RotationKeepsOldTokensValid(t *testing.T) {
+	oldKey := newEd25519Key(t, "2024-01")
+	newKey := newEd25519Key(t, "2024-06")
+	userID := uuid.New()
+
+	before, err := auth.NewKeyring(oldKey)
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	oldToken, err := before.MakeAccessToken(auth.Principal{UserID: userID}, time.Minute)
+	if err != nil {
+		t.Fatalf("MakeAccessToken failed: %v", err)
+	}
+
+	after, err := auth.NewKeyring(newKey, oldKey)
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	newToken, err := after.MakeAccessToken(auth.Principal{UserID: userID}, time.Minute)
+	if err != nil {
+		t.Fatalf("MakeAccessToken failed: %v", err)
+	}
+
+	for _, token := range []string{oldToken, newToken} {
+		got, err := after.ParseAccessToken(token)
+		if err != nil {
+			t.Fatalf("ParseAccessToken failed: %v", err)
+		}
+		if got.UserID != userID {
+			t.Errorf("Expected user ID %v, got %v", userID, got.UserID)
+		}
+	}
+
+	if _, err := before.ParseAccessToken(newToken); err == nil {
+		t.Error("Expected a token signed with an unknown key to be rejected")
+	}
+}
+
+func TestKeyring_AcceptsLegacyHMACTokens(t *testing.T) {
+	userID := uuid.New()
+	legacyToken, err := auth.MakeJWT(userID, "test-secret", time.Minute)
+	if err != nil {
+		t.Fatalf("MakeJWT failed: %v", err)
+	}
+
+	keyring, err := auth.NewKeyring(newEd25519Key(t, "ed"), auth.NewHMACKey("default", []byte("test-secret")))
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+
+	got, err := keyring.ParseAccessToken(legacyToken)
+	if err != nil {
+		t.Fatalf("ParseAccessToken failed: %v", err)
+	}
+	if got.UserID != userID {
+		t.Errorf("Expected user ID %v, got %v", userID, got.UserID)
+	}
+}
+
+func TestKeyring_RSAKeyFromPEM(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey failed: %v", err)
+	}
+
+	key, err := auth.ParsePrivateKeyPEM("rsa-1", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("ParsePrivateKeyPEM failed: %v", err)
+	}
+
+	keyring, err := auth.NewKeyring(key)
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	token, err := keyring.MakeAccessToken(auth.Principal{UserID: uuid.New()}, time.Minute)
+	if err != nil {
+		t.Fatalf("MakeAccessToken failed: %v", err)
+	}
+	if _, err := keyring.ParseAccessToken(token); err != nil {
+		t.Fatalf("ParseAccessToken failed: %v", err)
+	}
+}
+
+func TestKeyring_JWKSPublishesOnlyPublicKeys(t *testing.T) {
+	keyring, err := auth.NewKeyring(newEd25519Key(t, "ed"), auth.NewHMACKey("default", []byte("test-secret")))
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+
+	set := keyring.JWKS()
+	if len(set.Keys) != 1 {
+		t.Fatalf("Expected 1 key, got %d", len(set.Keys))
+	}
+	jwk := set.Keys[0]
+	if jwk.KeyID != "ed" || jwk.KeyType != "OKP" || jwk.Curve != "Ed25519" || jwk.Algorithm != "EdDSA" || jwk.X == "" {
+		t.Errorf("Unexpected JWK: %+v", jwk)
+	}
+}
+
+func TestNewKeyring_RejectsDuplicateIDs(t *testing.T) {
+	if _, err := auth.NewKeyring(newEd25519Key(t, "same"), newEd25519Key(t, "same")); err == nil {
+		t.Error("Expected an error for duplicate key IDs")
+	}
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+)
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the caller the middleware resolved for the
+// request. It reports false for anonymous requests.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// ErrorWriter writes an error response, so the middleware answers in the
+// same format as the rest of the API.
+type ErrorWriter func(w http.ResponseWriter, code int, msg string) error
+
+// Middleware authenticates requests once, before they reach a handler,
+// and stores the Principal in the request context.
+type Middleware struct {
+	authn      *Authenticator
+	writeError ErrorWriter
+}
+
+func NewMiddleware(authn *Authenticator, writeError ErrorWriter) *Middleware {
+	return &Middleware{authn: authn, writeError: writeError}
+}
+
+// Require lets a request through only with a valid bearer token carrying
+// scope. A missing or invalid token gets a 401, a token without the scope
+// a 403.
+func (m *Middleware) Require(scope Scope, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := m.authenticate(w, r)
+		if !ok {
+			return
+		}
+
+		if !p.HasScope(scope) {
+			m.writeError(w, http.StatusForbidden, "Token is missing the "+string(scope)+" scope")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+	})
+}
+
+// RequireRole lets a request through only for callers with at least role.
+// As with Require, a missing or invalid token gets a 401 and a caller
+// without the role a 403.
+func (m *Middleware) RequireRole(role Role, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := m.authenticate(w, r)
+		if !ok {
+			return
+		}
+
+		if !p.HasRole(role) {
+			m.writeError(w, http.StatusForbidden, "You are not authorized to access this")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+	})
+}
+
+func (m *Middleware) authenticate(w http.ResponseWriter, r *http.Request) (Principal, bool) {
+	token, err := GetBearerToken(r.Header)
+	if err != nil {
+		m.writeError(w, http.StatusUnauthorized, "Missing or invalid Authorization header")
+		return Principal{}, false
+	}
+
+	p, err := m.authn.Authenticate(r.Context(), token)
+	if err != nil {
+		m.writeError(w, http.StatusUnauthorized, "Invalid or expired token")
+		return Principal{}, false
+	}
+	return p, true
+}
+
+// Optional resolves the caller when the request has a bearer token and
+// lets anonymous requests through as they are. Endpoints like this only
+// personalise their responses, so an invalid token is treated as no token.
+func (m *Middleware) Optional(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := GetBearerToken(r.Header)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		p, err := m.authn.Authenticate(r.Context(), token)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+	})
+}
@@ -0,0 +1,141 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github/anansi-1/Chirpy/internal/auth"
+
+	"github.com/google/uuid"
+)
+
+func writeStatus(w http.ResponseWriter, code int, msg string) error {
+	w.WriteHeader(code)
+	return nil
+}
+
+func TestMiddleware_Require(t *testing.T) {
+	keyring := auth.NewHMACKeyring("test-secret")
+	userID := uuid.New()
+	loginToken, err := keyring.MakeAccessToken(auth.Principal{UserID: userID, SessionID: uuid.New()}, time.Minute)
+	if err != nil {
+		t.Fatalf("MakeAccessToken failed: %v", err)
+	}
+	patToken, err := auth.MakePersonalAccessToken()
+	if err != nil {
+		t.Fatalf("MakePersonalAccessToken failed: %v", err)
+	}
+
+	m := auth.NewMiddleware(auth.NewAuthenticator(keyring, fakeStore{
+		tokens: map[string]auth.Principal{
+			auth.HashPersonalAccessToken(patToken): {UserID: userID, Scopes: []auth.Scope{auth.ScopeChirpsRead}},
+		},
+	}), writeStatus)
+
+	var got auth.Principal
+	handler := m.Require(auth.ScopeChirpsWrite, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = auth.PrincipalFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"invalid token", "Bearer not-a-token", http.StatusUnauthorized},
+		{"missing scope", "Bearer " + patToken, http.StatusForbidden},
+		{"login token", "Bearer " + loginToken, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+
+	if got.UserID != userID {
+		t.Errorf("Expected principal for %s in context, got %+v", userID, got)
+	}
+}
+
+func TestMiddleware_Optional(t *testing.T) {
+	keyring := auth.NewHMACKeyring("test-secret")
+	m := auth.NewMiddleware(auth.NewAuthenticator(keyring, fakeStore{}), writeStatus)
+
+	var found bool
+	handler := m.Optional(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, found = auth.PrincipalFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer not-a-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || found {
+		t.Errorf("Expected invalid token to be served anonymously, got status %d with principal %v", rec.Code, found)
+	}
+
+	token, err := keyring.MakeAccessToken(auth.Principal{UserID: uuid.New()}, time.Minute)
+	if err != nil {
+		t.Fatalf("MakeAccessToken failed: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if !found {
+		t.Error("Expected principal in context for a valid token")
+	}
+}
+
+func TestMiddleware_RequireRole(t *testing.T) {
+	keyring := auth.NewHMACKeyring("test-secret")
+	m := auth.NewMiddleware(auth.NewAuthenticator(keyring, fakeStore{}), writeStatus)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name     string
+		required auth.Role
+		role     auth.Role
+		want     int
+	}{
+		{"user on admin route", auth.RoleAdmin, auth.RoleUser, http.StatusForbidden},
+		{"moderator on admin route", auth.RoleAdmin, auth.RoleModerator, http.StatusForbidden},
+		{"admin on admin route", auth.RoleAdmin, auth.RoleAdmin, http.StatusOK},
+		{"user on moderator route", auth.RoleModerator, auth.RoleUser, http.StatusForbidden},
+		{"moderator on moderator route", auth.RoleModerator, auth.RoleModerator, http.StatusOK},
+		{"admin on moderator route", auth.RoleModerator, auth.RoleAdmin, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := keyring.MakeAccessToken(auth.Principal{UserID: uuid.New(), Role: tt.role}, time.Minute)
+			if err != nil {
+				t.Fatalf("MakeAccessToken failed: %v", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/admin/metrics", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			m.RequireRole(tt.required, ok).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	m.RequireRole(auth.RoleAdmin, ok).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/metrics", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, rec.Code)
+	}
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Scope is a permission an access token carries. Each handler names the
+// scope it needs.
+type Scope string
+
+const (
+	ScopeChirpsRead   Scope = "chirps:read"
+	ScopeChirpsWrite  Scope = "chirps:write"
+	ScopeProfileWrite Scope = "profile:write"
+
+	// ScopeCredentials covers changing the account's email and password
+	// and managing sessions and personal access tokens. Only tokens from
+	// a password login have it; it cannot be granted to a personal access
+	// token.
+	ScopeCredentials Scope = "credentials"
+)
+
+// TokenScopes are the scopes a personal access token may be given.
+var TokenScopes = []Scope{ScopeChirpsRead, ScopeChirpsWrite, ScopeProfileWrite}
+
+// loginScopes are the scopes of a token from a password login.
+var loginScopes = []Scope{ScopeChirpsRead, ScopeChirpsWrite, ScopeProfileWrite, ScopeCredentials}
+
+// Role is what a user may do beyond managing their own account. Each role
+// includes the ones before it.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+var roleRanks = map[Role]int{RoleUser: 0, RoleModerator: 1, RoleAdmin: 2}
+
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+	if _, ok := roleRanks[role]; !ok {
+		return "", fmt.Errorf("unknown role %q", name)
+	}
+	return role, nil
+}
+
+// Includes reports whether r grants everything other does.
+func (r Role) Includes(other Role) bool {
+	return roleRanks[r] >= roleRanks[other]
+}
+
+var (
+	ErrSessionRevoked = errors.New("session revoked")
+	ErrTokenNotFound  = errors.New("access token not found or expired")
+)
+
+// ParseScopes checks requested personal access token scopes and returns
+// them without duplicates.
+func ParseScopes(names []string) ([]Scope, error) {
+	scopes := make([]Scope, 0, len(names))
+	for _, name := range names {
+		scope := Scope(strings.TrimSpace(name))
+		if !slices.Contains(TokenScopes, scope) {
+			return nil, fmt.Errorf("unknown scope %q", name)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// Principal is the caller behind an access token.
+type Principal struct {
+	UserID uuid.UUID
+	// SessionID is set for tokens from a login, TokenID for personal
+	// access tokens.
+	SessionID uuid.UUID
+	TokenID   uuid.UUID
+	Scopes    []Scope
+	// Role comes from the token for logins. Personal access tokens always
+	// act with the user role.
+	Role Role
+}
+
+func (p Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+func (p Principal) HasRole(role Role) bool {
+	return p.Role.Includes(role)
+}
+
+// CredentialStore looks up the server-side state behind access tokens.
+type CredentialStore interface {
+	// SessionActive reports whether userID's login session may still be
+	// used.
+	SessionActive(ctx context.Context, userID, sessionID uuid.UUID) (bool, error)
+	// PersonalAccessToken returns the principal for the token with the
+	// given hash, or ErrTokenNotFound.
+	PersonalAccessToken(ctx context.Context, hash string) (Principal, error)
+}
+
+// Authenticator turns a bearer token into a Principal. It accepts both
+// JWTs from a login and personal access tokens.
+type Authenticator struct {
+	keyring *Keyring
+	store   CredentialStore
+}
+
+func NewAuthenticator(keyring *Keyring, store CredentialStore) *Authenticator {
+	return &Authenticator{keyring: keyring, store: store}
+}
+
+// Authenticate validates token. A login token whose session has been
+// revoked is refused straight away rather than when it expires. Personal
+// access tokens never carry more than the user role, so a leaked bot
+// token cannot reach admin endpoints.
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (Principal, error) {
+	if IsPersonalAccessToken(token) {
+		p, err := a.store.PersonalAccessToken(ctx, HashPersonalAccessToken(token))
+		if err != nil {
+			return Principal{}, err
+		}
+		p.Role = RoleUser
+		return p, nil
+	}
+
+	p, err := a.keyring.ParseAccessToken(token)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	if p.SessionID != uuid.Nil {
+		active, err := a.store.SessionActive(ctx, p.UserID, p.SessionID)
+		if err != nil {
+			return Principal{}, err
+		}
+		if !active {
+			return Principal{}, ErrSessionRevoked
+		}
+	}
+
+	p.Scopes = loginScopes
+	return p, nil
+}
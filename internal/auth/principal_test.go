@@ -0,0 +1,168 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github/anansi-1/Chirpy/internal/auth"
+
+	"github.com/google/uuid"
+)
+
+type fakeStore struct {
+	revoked map[uuid.UUID]bool
+	tokens  map[string]auth.Principal
+}
+
+func (s fakeStore) SessionActive(ctx context.Context, userID, sessionID uuid.UUID) (bool, error) {
+	return !s.revoked[sessionID], nil
+}
+
+func (s fakeStore) PersonalAccessToken(ctx context.Context, hash string) (auth.Principal, error) {
+	p, ok := s.tokens[hash]
+	if !ok {
+		return auth.Principal{}, auth.ErrTokenNotFound
+	}
+	return p, nil
+}
+
+func TestAuthenticate_LoginTokenHasAllScopes(t *testing.T) {
+	keyring := auth.NewHMACKeyring("test-secret")
+	userID, sessionID := uuid.New(), uuid.New()
+	token, err := keyring.MakeAccessToken(auth.Principal{UserID: userID, SessionID: sessionID}, time.Minute)
+	if err != nil {
+		t.Fatalf("MakeAccessToken failed: %v", err)
+	}
+
+	a := auth.NewAuthenticator(keyring, fakeStore{})
+	p, err := a.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if p.UserID != userID || p.SessionID != sessionID {
+		t.Errorf("Unexpected principal: %+v", p)
+	}
+	for _, scope := range append(auth.TokenScopes, auth.ScopeCredentials) {
+		if !p.HasScope(scope) {
+			t.Errorf("Expected login token to have scope %q", scope)
+		}
+	}
+}
+
+func TestAuthenticate_RevokedSession(t *testing.T) {
+	keyring := auth.NewHMACKeyring("test-secret")
+	sessionID := uuid.New()
+	token, err := keyring.MakeAccessToken(auth.Principal{UserID: uuid.New(), SessionID: sessionID}, time.Minute)
+	if err != nil {
+		t.Fatalf("MakeAccessToken failed: %v", err)
+	}
+
+	a := auth.NewAuthenticator(keyring, fakeStore{revoked: map[uuid.UUID]bool{sessionID: true}})
+	if _, err := a.Authenticate(context.Background(), token); !errors.Is(err, auth.ErrSessionRevoked) {
+		t.Errorf("Expected %v, got %v", auth.ErrSessionRevoked, err)
+	}
+}
+
+func TestAuthenticate_PersonalAccessToken(t *testing.T) {
+	token, err := auth.MakePersonalAccessToken()
+	if err != nil {
+		t.Fatalf("MakePersonalAccessToken failed: %v", err)
+	}
+	want := auth.Principal{UserID: uuid.New(), TokenID: uuid.New(), Scopes: []auth.Scope{auth.ScopeChirpsWrite}}
+
+	a := auth.NewAuthenticator(auth.NewHMACKeyring("test-secret"), fakeStore{
+		tokens: map[string]auth.Principal{auth.HashPersonalAccessToken(token): want},
+	})
+
+	p, err := a.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if p.UserID != want.UserID || !p.HasScope(auth.ScopeChirpsWrite) || p.HasScope(auth.ScopeChirpsRead) {
+		t.Errorf("Unexpected principal: %+v", p)
+	}
+
+	other, err := auth.MakePersonalAccessToken()
+	if err != nil {
+		t.Fatalf("MakePersonalAccessToken failed: %v", err)
+	}
+	if _, err := a.Authenticate(context.Background(), other); !errors.Is(err, auth.ErrTokenNotFound) {
+		t.Errorf("Expected %v, got %v", auth.ErrTokenNotFound, err)
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := auth.ParseScopes([]string{"chirps:read", "chirps:write", "chirps:read"})
+	if err != nil {
+		t.Fatalf("ParseScopes failed: %v", err)
+	}
+	if len(scopes) != 2 {
+		t.Errorf("Expected duplicates to be dropped, got %v", scopes)
+	}
+
+	if _, err := auth.ParseScopes([]string{"credentials"}); err == nil {
+		t.Error("Expected credentials to be refused for personal access tokens")
+	}
+}
+
+func TestAuthenticate_RoleFromToken(t *testing.T) {
+	keyring := auth.NewHMACKeyring("test-secret")
+	token, err := keyring.MakeAccessToken(auth.Principal{UserID: uuid.New(), SessionID: uuid.New(), Role: auth.RoleAdmin}, time.Minute)
+	if err != nil {
+		t.Fatalf("MakeAccessToken failed: %v", err)
+	}
+
+	a := auth.NewAuthenticator(keyring, fakeStore{})
+	p, err := a.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if !p.HasRole(auth.RoleAdmin) || !p.HasRole(auth.RoleModerator) {
+		t.Errorf("Expected admin role, got %q", p.Role)
+	}
+
+	// Tokens issued before roles existed carry no role claim.
+	legacy, err := keyring.MakeAccessToken(auth.Principal{UserID: uuid.New()}, time.Minute)
+	if err != nil {
+		t.Fatalf("MakeAccessToken failed: %v", err)
+	}
+	p, err = a.Authenticate(context.Background(), legacy)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if p.Role != auth.RoleUser {
+		t.Errorf("Expected %q, got %q", auth.RoleUser, p.Role)
+	}
+}
+
+func TestAuthenticate_PersonalAccessTokenHasUserRole(t *testing.T) {
+	token, err := auth.MakePersonalAccessToken()
+	if err != nil {
+		t.Fatalf("MakePersonalAccessToken failed: %v", err)
+	}
+
+	a := auth.NewAuthenticator(auth.NewHMACKeyring("test-secret"), fakeStore{
+		tokens: map[string]auth.Principal{auth.HashPersonalAccessToken(token): {UserID: uuid.New(), Role: auth.RoleAdmin}},
+	})
+
+	p, err := a.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if p.HasRole(auth.RoleModerator) {
+		t.Errorf("Expected personal access token to act as a plain user, got %q", p.Role)
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	for _, name := range []string{"user", "moderator", "admin"} {
+		if _, err := auth.ParseRole(name); err != nil {
+			t.Errorf("ParseRole(%q) failed: %v", name, err)
+		}
+	}
+	if _, err := auth.ParseRole("root"); err == nil {
+		t.Error("Expected unknown role to be refused")
+	}
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	WebhookTimestampHeader = "Webhook-Timestamp"
+	WebhookSignatureHeader = "Webhook-Signature"
+)
+
+var (
+	ErrWebhookSignatureMissing = errors.New("webhook signature headers missing")
+	ErrWebhookSignatureInvalid = errors.New("webhook signature does not match")
+	ErrWebhookTimestampExpired = errors.New("webhook timestamp outside tolerance")
+)
+
+// MakeWebhookSecret returns a random secret for signing deliveries to one
+// webhook endpoint.
+func MakeWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "<unix timestamp>.<body>".
+// Including the timestamp stops an old delivery from being replayed with a
+// fresh one.
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	return hex.EncodeToString(webhookMAC(secret, strconv.FormatInt(timestamp.Unix(), 10), body))
+}
+
+// ValidateWebhookSignature checks the signature headers of a webhook
+// delivery against its raw body. Deliveries signed more than tolerance
+// before or after now are rejected.
+func ValidateWebhookSignature(headers http.Header, body []byte, secret string, tolerance time.Duration) error {
+	timestamp := headers.Get(WebhookTimestampHeader)
+	signature := headers.Get(WebhookSignatureHeader)
+	if timestamp == "" || signature == "" {
+		return ErrWebhookSignatureMissing
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrWebhookSignatureInvalid
+	}
+	age := time.Since(time.Unix(unix, 0))
+	if age > tolerance || age < -tolerance {
+		return ErrWebhookTimestampExpired
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrWebhookSignatureInvalid
+	}
+	if !hmac.Equal(got, webhookMAC(secret, timestamp, body)) {
+		return ErrWebhookSignatureInvalid
+	}
+
+	return nil
+}
+
+func webhookMAC(secret, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,82 @@
+package auth_test
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github/anansi-1/Chirpy/internal/auth"
+)
+
+func signedHeaders(secret string, timestamp time.Time, body []byte) http.Header {
+	headers := http.Header{}
+	headers.Set(auth.WebhookTimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	headers.Set(auth.WebhookSignatureHeader, auth.SignWebhook(secret, timestamp, body))
+	return headers
+}
+
+func TestValidateWebhookSignature_ValidSignature(t *testing.T) {
+	body := []byte(`{"id":"evt_1","event":"user.upgraded"}`)
+	headers := signedHeaders("test-secret", time.Now(), body)
+
+	if err := auth.ValidateWebhookSignature(headers, body, "test-secret", 5*time.Minute); err != nil {
+		t.Fatalf("ValidateWebhookSignature failed: %v", err)
+	}
+}
+
+func TestValidateWebhookSignature_TamperedBody(t *testing.T) {
+	body := []byte(`{"id":"evt_1","event":"user.upgraded"}`)
+	headers := signedHeaders("test-secret", time.Now(), body)
+
+	tampered := []byte(`{"id":"evt_1","event":"user.downgraded"}`)
+	err := auth.ValidateWebhookSignature(headers, tampered, "test-secret", 5*time.Minute)
+	if !errors.Is(err, auth.ErrWebhookSignatureInvalid) {
+		t.Errorf("Expected %v, got %v", auth.ErrWebhookSignatureInvalid, err)
+	}
+}
+
+func TestValidateWebhookSignature_WrongSecret(t *testing.T) {
+	body := []byte(`{}`)
+	headers := signedHeaders("other-secret", time.Now(), body)
+
+	err := auth.ValidateWebhookSignature(headers, body, "test-secret", 5*time.Minute)
+	if !errors.Is(err, auth.ErrWebhookSignatureInvalid) {
+		t.Errorf("Expected %v, got %v", auth.ErrWebhookSignatureInvalid, err)
+	}
+}
+
+func TestValidateWebhookSignature_OutsideTolerance(t *testing.T) {
+	body := []byte(`{}`)
+
+	for _, timestamp := range []time.Time{time.Now().Add(-10 * time.Minute), time.Now().Add(10 * time.Minute)} {
+		headers := signedHeaders("test-secret", timestamp, body)
+		err := auth.ValidateWebhookSignature(headers, body, "test-secret", 5*time.Minute)
+		if !errors.Is(err, auth.ErrWebhookTimestampExpired) {
+			t.Errorf("Expected %v, got %v", auth.ErrWebhookTimestampExpired, err)
+		}
+	}
+}
+
+func TestValidateWebhookSignature_MissingHeaders(t *testing.T) {
+	err := auth.ValidateWebhookSignature(http.Header{}, []byte(`{}`), "test-secret", 5*time.Minute)
+	if !errors.Is(err, auth.ErrWebhookSignatureMissing) {
+		t.Errorf("Expected %v, got %v", auth.ErrWebhookSignatureMissing, err)
+	}
+}
+
+func TestMakeWebhookSecret_Unique(t *testing.T) {
+	first, err := auth.MakeWebhookSecret()
+	if err != nil {
+		t.Fatalf("MakeWebhookSecret failed: %v", err)
+	}
+	second, err := auth.MakeWebhookSecret()
+	if err != nil {
+		t.Fatalf("MakeWebhookSecret failed: %v", err)
+	}
+
+	if first == second {
+		t.Error("Expected different secrets")
+	}
+}
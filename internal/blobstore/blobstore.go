@@ -0,0 +1,19 @@
+// Package blobstore stores uploaded files behind a small interface so the
+// server does not care whether they live on local disk or in an
+// S3-compatible bucket.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned by Get when no object exists under the key.
+var ErrNotFound = errors.New("blob not found")
+
+type Store interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,99 @@
+package blobstore_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github/anansi-1/Chirpy/internal/blobstore"
+)
+
+// memoryClient is an in-process stand-in for an S3-compatible service.
+type memoryClient struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (c *memoryClient) PutObject(ctx context.Context, bucket, key string, body io.Reader, contentType string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.objects[bucket+"/"+key] = data
+	return nil
+}
+
+func (c *memoryClient) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.objects[bucket+"/"+key]
+	if !ok {
+		return nil, blobstore.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (c *memoryClient) DeleteObject(ctx context.Context, bucket, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.objects, bucket+"/"+key)
+	return nil
+}
+
+func testStore(t *testing.T, store blobstore.Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "a.png", strings.NewReader("image bytes"), "image/png"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	rc, err := store.Get(ctx, "a.png")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "image bytes" {
+		t.Errorf("Expected %q, got %q", "image bytes", data)
+	}
+
+	if err := store.Delete(ctx, "a.png"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	if _, err := store.Get(ctx, "a.png"); !errors.Is(err, blobstore.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore failed: %v", err)
+	}
+	testStore(t, store)
+}
+
+func TestLocalStore_RejectsPathTraversal(t *testing.T) {
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore failed: %v", err)
+	}
+
+	err = store.Put(context.Background(), "../escape", strings.NewReader("x"), "text/plain")
+	if err == nil {
+		t.Fatal("Expected error for key outside the store root, got nil")
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	client := &memoryClient{objects: map[string][]byte{}}
+	testStore(t, blobstore.NewS3Store(client, "chirpy", "media/"))
+}
@@ -0,0 +1,40 @@
+package blobstore
+
+import (
+	"context"
+	"io"
+)
+
+// ObjectClient is the subset of the S3 API the store needs. The AWS SDK
+// client, MinIO, or a local stand-in used in development and tests can all
+// satisfy it through a thin adapter. GetObject must return ErrNotFound for
+// missing keys.
+type ObjectClient interface {
+	PutObject(ctx context.Context, bucket, key string, body io.Reader, contentType string) error
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	DeleteObject(ctx context.Context, bucket, key string) error
+}
+
+// S3Store keeps blobs in a single bucket of an S3-compatible service.
+type S3Store struct {
+	client ObjectClient
+	bucket string
+	prefix string
+}
+
+// NewS3Store returns a store that writes every key under prefix in bucket.
+func NewS3Store(client ObjectClient, bucket, prefix string) *S3Store {
+	return &S3Store{client: client, bucket: bucket, prefix: prefix}
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	return s.client.PutObject(ctx, s.bucket, s.prefix+key, body, contentType)
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.client.GetObject(ctx, s.bucket, s.prefix+key)
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	return s.client.DeleteObject(ctx, s.bucket, s.prefix+key)
+}
@@ -7,29 +7,62 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countChirpsByUserSince = `-- name: CountChirpsByUserSince :one
+SELECT COUNT(*)
+FROM chirps
+WHERE user_id = $1 AND created_at >= $2::timestamp
+`
+
+type CountChirpsByUserSinceParams struct {
+	UserID uuid.NullUUID
+	Since  time.Time
+}
+
+func (q *Queries) CountChirpsByUserSince(ctx context.Context, arg CountChirpsByUserSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChirpsByUserSince, arg.UserID, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_id, publish_at, visibility)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3,
+    $4,
+    $5
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.NullUUID
+	Body       string
+	UserID     uuid.NullUUID
+	ParentID   uuid.NullUUID
+	PublishAt  sql.NullTime
+	Visibility string
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.ParentID,
+		arg.PublishAt,
+		arg.Visibility,
+	)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,10 +70,102 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.ParentID,
+		&i.ReplyCount,
+		&i.IsTombstone,
+		&i.LikeCount,
+		&i.Kind,
+		&i.OriginalChirpID,
+		&i.PublishAt,
+		&i.Visibility,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
+const createRechirp = `-- name: CreateRechirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, kind, original_chirp_id)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+`
+
+type CreateRechirpParams struct {
+	Body            string
+	UserID          uuid.NullUUID
+	Kind            string
+	OriginalChirpID uuid.NullUUID
+}
+
+func (q *Queries) CreateRechirp(ctx context.Context, arg CreateRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, createRechirp,
+		arg.Body,
+		arg.UserID,
+		arg.Kind,
+		arg.OriginalChirpID,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.ReplyCount,
+		&i.IsTombstone,
+		&i.LikeCount,
+		&i.Kind,
+		&i.OriginalChirpID,
+		&i.PublishAt,
+		&i.Visibility,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const decrementLikeCount = `-- name: DecrementLikeCount :exec
+UPDATE chirps
+SET like_count = GREATEST(like_count - 1, 0)
+WHERE id = $1
+`
+
+func (q *Queries) DecrementLikeCount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, decrementLikeCount, id)
+	return err
+}
+
+const decrementReplyCount = `-- name: DecrementReplyCount :exec
+UPDATE chirps
+SET reply_count = GREATEST(reply_count - 1, 0)
+WHERE id = $1
+`
+
+func (q *Queries) DecrementReplyCount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, decrementReplyCount, id)
+	return err
+}
+
+const deleteChirpWithoutReplies = `-- name: DeleteChirpWithoutReplies :execrows
+DELETE FROM chirps
+WHERE id = $1 AND reply_count = 0
+`
+
+func (q *Queries) DeleteChirpWithoutReplies(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteChirpWithoutReplies, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteChirpsByID = `-- name: DeleteChirpsByID :exec
 DELETE FROM chirps
 WHERE id = $1
@@ -52,8 +177,9 @@ func (q *Queries) DeleteChirpsByID(ctx context.Context, id uuid.UUID) error {
 }
 
 const getAllChirps = `-- name: GetAllChirps :many
-SELECT id, created_at, updated_at, body, user_id 
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
 FROM chirps
+WHERE NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL AND visibility = 'public'
 ORDER BY created_at ASC
 `
 
@@ -72,6 +198,114 @@ func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.ParentID,
+			&i.ReplyCount,
+			&i.IsTombstone,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.PublishAt,
+			&i.Visibility,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpThread = `-- name: GetChirpThread :many
+WITH RECURSIVE thread AS (
+    SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at, 0 AS depth
+    FROM chirps
+    WHERE chirps.id = $1
+    AND chirps.publish_at IS NULL
+    AND (
+      chirps.visibility = 'public'
+      OR chirps.user_id = $2::uuid
+      OR (chirps.visibility = 'followers' AND chirps.user_id IN (
+        SELECT followee_id
+        FROM follows
+        WHERE follower_id = $2::uuid
+      ))
+    )
+    UNION ALL
+    SELECT c.id, c.created_at, c.updated_at, c.body, c.user_id, c.parent_id, c.reply_count, c.is_tombstone, c.like_count, c.kind, c.original_chirp_id, c.publish_at, c.visibility, c.deleted_at, t.depth + 1
+    FROM chirps c
+    JOIN thread t ON c.parent_id = t.id
+    WHERE t.depth < $3::int
+      AND c.publish_at IS NULL
+      AND (
+        c.visibility = 'public'
+        OR c.user_id = $2::uuid
+        OR (c.visibility = 'followers' AND c.user_id IN (
+          SELECT followee_id
+          FROM follows
+          WHERE follower_id = $2::uuid
+        ))
+      )
+)
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at, depth
+FROM thread
+ORDER BY depth ASC, created_at ASC, id ASC
+`
+
+type GetChirpThreadParams struct {
+	RootID   uuid.UUID
+	ViewerID uuid.NullUUID
+	MaxDepth int32
+}
+
+type GetChirpThreadRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Body            string
+	UserID          uuid.NullUUID
+	ParentID        uuid.NullUUID
+	ReplyCount      int32
+	IsTombstone     bool
+	LikeCount       int32
+	Kind            string
+	OriginalChirpID uuid.NullUUID
+	PublishAt       sql.NullTime
+	Visibility      string
+	DeletedAt       sql.NullTime
+	Depth           int32
+}
+
+func (q *Queries) GetChirpThread(ctx context.Context, arg GetChirpThreadParams) ([]GetChirpThreadRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpThread, arg.RootID, arg.ViewerID, arg.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpThreadRow
+	for rows.Next() {
+		var i GetChirpThreadRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.ReplyCount,
+			&i.IsTombstone,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.PublishAt,
+			&i.Visibility,
+			&i.DeletedAt,
+			&i.Depth,
 		); err != nil {
 			return nil, err
 		}
@@ -87,9 +321,9 @@ func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
 }
 
 const getChirpsByAuthorID = `-- name: GetChirpsByAuthorID :many
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
 FROM chirps
-WHERE user_id = $1
+WHERE user_id = $1 AND NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL AND visibility = 'public'
 ORDER BY created_at ASC
 `
 
@@ -108,6 +342,15 @@ func (q *Queries) GetChirpsByAuthorID(ctx context.Context, userID uuid.NullUUID)
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.ParentID,
+			&i.ReplyCount,
+			&i.IsTombstone,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.PublishAt,
+			&i.Visibility,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -123,13 +366,620 @@ func (q *Queries) GetChirpsByAuthorID(ctx context.Context, userID uuid.NullUUID)
 }
 
 const getChirpsByID = `-- name: GetChirpsByID :one
-SELECT id, created_at, updated_at, body, user_id 
-FROM chirps 
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE id = $1 AND NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = $2::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = $2::uuid
+    ))
+  )
+`
+
+type GetChirpsByIDParams struct {
+	ID       uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) GetChirpsByID(ctx context.Context, arg GetChirpsByIDParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpsByID, arg.ID, arg.ViewerID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.ReplyCount,
+		&i.IsTombstone,
+		&i.LikeCount,
+		&i.Kind,
+		&i.OriginalChirpID,
+		&i.PublishAt,
+		&i.Visibility,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getChirpsByIDs = `-- name: GetChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE id = ANY($1::uuid[]) AND NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = $2::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = $2::uuid
+    ))
+  )
+`
+
+type GetChirpsByIDsParams struct {
+	Ids      []uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) GetChirpsByIDs(ctx context.Context, arg GetChirpsByIDsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIDs, pq.Array(arg.Ids), arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.ReplyCount,
+			&i.IsTombstone,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.PublishAt,
+			&i.Visibility,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const incrementLikeCount = `-- name: IncrementLikeCount :exec
+UPDATE chirps
+SET like_count = like_count + 1
+WHERE id = $1
+`
+
+func (q *Queries) IncrementLikeCount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, incrementLikeCount, id)
+	return err
+}
+
+const incrementReplyCount = `-- name: IncrementReplyCount :exec
+UPDATE chirps
+SET reply_count = reply_count + 1
+WHERE id = $1
+`
+
+func (q *Queries) IncrementReplyCount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, incrementReplyCount, id)
+	return err
+}
+
+const listChirpsAsc = `-- name: ListChirpsAsc :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = $1::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = $1::uuid
+    ))
+  )
+  AND ($2::uuid IS NULL OR user_id = $2::uuid)
+  AND (
+    $3::timestamp IS NULL
+    OR (created_at, id) > ($3::timestamp, $4::uuid)
+  )
+ORDER BY created_at ASC, id ASC
+LIMIT $5
+`
+
+type ListChirpsAscParams struct {
+	ViewerID        uuid.NullUUID
+	AuthorID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	RowLimit        int32
+}
+
+func (q *Queries) ListChirpsAsc(ctx context.Context, arg ListChirpsAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsAsc,
+		arg.ViewerID,
+		arg.AuthorID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.ReplyCount,
+			&i.IsTombstone,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.PublishAt,
+			&i.Visibility,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsByTag = `-- name: ListChirpsByTag :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE id IN (
+    SELECT chirp_tags.chirp_id
+    FROM chirp_tags
+    JOIN tags ON tags.id = chirp_tags.tag_id
+    WHERE tags.name = $1
+  )
+  AND NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = $2::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = $2::uuid
+    ))
+  )
+  AND (
+    $3::timestamp IS NULL
+    OR (created_at, id) < ($3::timestamp, $4::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $5
+`
+
+type ListChirpsByTagParams struct {
+	Tag             string
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	RowLimit        int32
+}
+
+func (q *Queries) ListChirpsByTag(ctx context.Context, arg ListChirpsByTagParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByTag,
+		arg.Tag,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.ReplyCount,
+			&i.IsTombstone,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.PublishAt,
+			&i.Visibility,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsDesc = `-- name: ListChirpsDesc :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = $1::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = $1::uuid
+    ))
+  )
+  AND ($2::uuid IS NULL OR user_id = $2::uuid)
+  AND (
+    $3::timestamp IS NULL
+    OR (created_at, id) < ($3::timestamp, $4::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $5
+`
+
+type ListChirpsDescParams struct {
+	ViewerID        uuid.NullUUID
+	AuthorID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	RowLimit        int32
+}
+
+func (q *Queries) ListChirpsDesc(ctx context.Context, arg ListChirpsDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsDesc,
+		arg.ViewerID,
+		arg.AuthorID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.ReplyCount,
+			&i.IsTombstone,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.PublishAt,
+			&i.Visibility,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsMentioningUser = `-- name: ListChirpsMentioningUser :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE id IN (
+    SELECT chirp_id
+    FROM chirp_mentions
+    WHERE chirp_mentions.user_id = $1
+  )
+  AND NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = $2::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = $2::uuid
+    ))
+  )
+  AND (
+    $3::timestamp IS NULL
+    OR (created_at, id) < ($3::timestamp, $4::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $5
+`
+
+type ListChirpsMentioningUserParams struct {
+	MentionedUserID uuid.UUID
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	RowLimit        int32
+}
+
+func (q *Queries) ListChirpsMentioningUser(ctx context.Context, arg ListChirpsMentioningUserParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsMentioningUser,
+		arg.MentionedUserID,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.ReplyCount,
+			&i.IsTombstone,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.PublishAt,
+			&i.Visibility,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTimeline = `-- name: ListTimeline :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE user_id IN (
+    SELECT followee_id
+    FROM follows
+    WHERE follower_id = $1
+  )
+  AND NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = $2::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = $2::uuid
+    ))
+  )
+  AND (
+    $3::timestamp IS NULL
+    OR (created_at, id) < ($3::timestamp, $4::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $5
+`
+
+type ListTimelineParams struct {
+	FollowerID      uuid.UUID
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	RowLimit        int32
+}
+
+func (q *Queries) ListTimeline(ctx context.Context, arg ListTimelineParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listTimeline,
+		arg.FollowerID,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.ReplyCount,
+			&i.IsTombstone,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.PublishAt,
+			&i.Visibility,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockChirp = `-- name: LockChirp :exec
+SELECT id
+FROM chirps
 WHERE id = $1
+FOR UPDATE
 `
 
-func (q *Queries) GetChirpsByID(ctx context.Context, id uuid.UUID) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, getChirpsByID, id)
+func (q *Queries) LockChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, lockChirp, id)
+	return err
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE to_tsvector('english', body) @@ to_tsquery('english', $1)
+  AND NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = $2::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = $2::uuid
+    ))
+  )
+  AND ($3::uuid IS NULL OR user_id = $3::uuid)
+ORDER BY ts_rank(to_tsvector('english', body), to_tsquery('english', $1)) DESC, created_at DESC, id DESC
+LIMIT $4
+`
+
+type SearchChirpsParams struct {
+	Query    string
+	ViewerID uuid.NullUUID
+	AuthorID uuid.NullUUID
+	RowLimit int32
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps,
+		arg.Query,
+		arg.ViewerID,
+		arg.AuthorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.ReplyCount,
+			&i.IsTombstone,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.PublishAt,
+			&i.Visibility,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const tombstoneChirp = `-- name: TombstoneChirp :exec
+UPDATE chirps
+SET body = '',
+    user_id = NULL,
+    is_tombstone = true,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) TombstoneChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, tombstoneChirp, id)
+	return err
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+`
+
+type UpdateChirpBodyParams struct {
+	ID   uuid.UUID
+	Body string
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.ID, arg.Body)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -137,6 +987,15 @@ func (q *Queries) GetChirpsByID(ctx context.Context, id uuid.UUID) (Chirp, error
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.ParentID,
+		&i.ReplyCount,
+		&i.IsTombstone,
+		&i.LikeCount,
+		&i.Kind,
+		&i.OriginalChirpID,
+		&i.PublishAt,
+		&i.Visibility,
+		&i.DeletedAt,
 	)
 	return i, err
 }
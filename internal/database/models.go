@@ -6,26 +6,169 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type Attachment struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	ChirpID     uuid.UUID
+	UserID      uuid.UUID
+	StorageKey  string
+	ContentType string
+	SizeBytes   int64
+	Width       int32
+	Height      int32
+	Position    int32
+}
+
 type Chirp struct {
-	ID        uuid.UUID
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Body            string
+	UserID          uuid.NullUUID
+	ParentID        uuid.NullUUID
+	ReplyCount      int32
+	IsTombstone     bool
+	LikeCount       int32
+	Kind            string
+	OriginalChirpID uuid.NullUUID
+	PublishAt       sql.NullTime
+	Visibility      string
+	DeletedAt       sql.NullTime
+}
+
+type ChirpMention struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpRevision struct {
+	ID         uuid.UUID
+	ChirpID    uuid.UUID
+	Body       string
+	CreatedAt  time.Time
+	ReplacedAt time.Time
+}
+
+type ChirpTag struct {
+	ChirpID   uuid.UUID
+	TagID     uuid.UUID
+	CreatedAt time.Time
+}
+
+type FilterWord struct {
+	Word      string
+	Action    string
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Body      string
-	UserID    uuid.NullUUID
+}
+
+type FlaggedChirp struct {
+	ChirpID      uuid.UUID
+	MatchedWords []string
+	CreatedAt    time.Time
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type Like struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type PersonalAccessToken struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Name       string
+	TokenHash  string
+	Scopes     []string
+	ExpiresAt  sql.NullTime
+	LastUsedAt sql.NullTime
+	CreatedAt  time.Time
+}
+
+type ProcessedWebhookEvent struct {
+	Source      string
+	EventID     string
+	Event       string
+	ProcessedAt time.Time
 }
 
 type RefreshToken struct {
-	Token     string
+	Token      string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	ExpiresAt  time.Time
+	RevokedAt  sql.NullTime
+	FamilyID   uuid.UUID
+	ReplacedBy sql.NullString
+}
+
+type SecurityEvent struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Event     string
+	Detail    string
+	CreatedAt time.Time
+}
+
+type Session struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	DeviceLabel string
+	UserAgent   string
+	Ip          string
+	CreatedAt   time.Time
+	LastUsedAt  time.Time
+	ExpiresAt   time.Time
+	RevokedAt   sql.NullTime
+}
+
+type Subscription struct {
+	UserID    uuid.UUID
+	ExpiresAt sql.NullTime
 	CreatedAt time.Time
 	UpdatedAt time.Time
+}
+
+type SubscriptionEvent struct {
+	ID        uuid.UUID
 	UserID    uuid.UUID
-	ExpiresAt time.Time
-	RevokedAt sql.NullTime
+	Event     string
+	ExpiresAt sql.NullTime
+	CreatedAt time.Time
+}
+
+type Tag struct {
+	ID   uuid.UUID
+	Name string
+}
+
+type TrendingChirp struct {
+	TimeWindow  string
+	ChirpID     uuid.UUID
+	Score       float64
+	RefreshedAt time.Time
+}
+
+type TrendingTag struct {
+	TimeWindow  string
+	Tag         string
+	Score       float64
+	ChirpCount  int32
+	RefreshedAt time.Time
 }
 
 type User struct {
@@ -35,4 +178,42 @@ type User struct {
 	Email          string
 	HashedPassword string
 	IsChirpyRed    sql.NullBool
+	Handle         sql.NullString
+	Role           string
+	SuspendedAt    sql.NullTime
+}
+
+type WebhookDelivery struct {
+	ID            uuid.UUID
+	OutboxID      uuid.UUID
+	EndpointID    uuid.UUID
+	Status        string
+	Attempts      int32
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type WebhookDeliveryAttempt struct {
+	ID         uuid.UUID
+	DeliveryID uuid.UUID
+	StatusCode sql.NullInt32
+	Error      sql.NullString
+	CreatedAt  time.Time
+}
+
+type WebhookEndpoint struct {
+	ID        uuid.UUID
+	Url       string
+	Secret    string
+	Events    []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type WebhookOutbox struct {
+	ID        uuid.UUID
+	Event     string
+	Payload   json.RawMessage
+	CreatedAt time.Time
 }
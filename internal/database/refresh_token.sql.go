@@ -14,22 +14,28 @@ import (
 
 const createRefreshToken = `-- name: CreateRefreshToken :one
 INSERT INTO refresh_tokens (
-  token, created_at, updated_at, user_id, expires_at, revoked_at
+  token, created_at, updated_at, user_id, expires_at, revoked_at, family_id
 )
 VALUES (
-  $1, NOW(), NOW(), $2, $3, NULL
+  $1, NOW(), NOW(), $2, $3, NULL, $4
 )
-RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at
+RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at, family_id, replaced_by
 `
 
 type CreateRefreshTokenParams struct {
 	Token     string
 	UserID    uuid.UUID
 	ExpiresAt time.Time
+	FamilyID  uuid.UUID
 }
 
 func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error) {
-	row := q.db.QueryRowContext(ctx, createRefreshToken, arg.Token, arg.UserID, arg.ExpiresAt)
+	row := q.db.QueryRowContext(ctx, createRefreshToken,
+		arg.Token,
+		arg.UserID,
+		arg.ExpiresAt,
+		arg.FamilyID,
+	)
 	var i RefreshToken
 	err := row.Scan(
 		&i.Token,
@@ -38,12 +44,14 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshToken
 		&i.UserID,
 		&i.ExpiresAt,
 		&i.RevokedAt,
+		&i.FamilyID,
+		&i.ReplacedBy,
 	)
 	return i, err
 }
 
 const getRefreshToken = `-- name: GetRefreshToken :one
-SELECT token, created_at, updated_at, user_id, expires_at, revoked_at 
+SELECT token, created_at, updated_at, user_id, expires_at, revoked_at, family_id, replaced_by
 FROM refresh_tokens
 WHERE token = $1
 `
@@ -58,6 +66,36 @@ func (q *Queries) GetRefreshToken(ctx context.Context, token string) (RefreshTok
 		&i.UserID,
 		&i.ExpiresAt,
 		&i.RevokedAt,
+		&i.FamilyID,
+		&i.ReplacedBy,
+	)
+	return i, err
+}
+
+const getRefreshTokenReplacement = `-- name: GetRefreshTokenReplacement :one
+-- Returns the token that replaced $1 if the exchange happened within the
+-- last 30 seconds and the replacement is still the family's live token.
+SELECT next.token, next.created_at, next.updated_at, next.user_id, next.expires_at, next.revoked_at, next.family_id, next.replaced_by
+FROM refresh_tokens prev
+JOIN refresh_tokens next ON next.token = prev.replaced_by
+WHERE prev.token = $1
+  AND prev.revoked_at > NOW() - INTERVAL '30 seconds'
+  AND next.revoked_at IS NULL
+  AND next.expires_at > NOW()
+`
+
+func (q *Queries) GetRefreshTokenReplacement(ctx context.Context, token string) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, getRefreshTokenReplacement, token)
+	var i RefreshToken
+	err := row.Scan(
+		&i.Token,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.FamilyID,
+		&i.ReplacedBy,
 	)
 	return i, err
 }
@@ -73,3 +111,36 @@ func (q *Queries) RevokeRefreshToken(ctx context.Context, token string) error {
 	_, err := q.db.ExecContext(ctx, revokeRefreshToken, token)
 	return err
 }
+
+const revokeRefreshTokenFamily = `-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW(),
+    updated_at = NOW()
+WHERE family_id = $1 AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, revokeRefreshTokenFamily, familyID)
+	return err
+}
+
+const rotateRefreshToken = `-- name: RotateRefreshToken :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW(),
+    replaced_by = $1,
+    updated_at = NOW()
+WHERE token = $2 AND revoked_at IS NULL
+`
+
+type RotateRefreshTokenParams struct {
+	ReplacedBy string
+	Token      string
+}
+
+func (q *Queries) RotateRefreshToken(ctx context.Context, arg RotateRefreshTokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rotateRefreshToken, arg.ReplacedBy, arg.Token)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -14,20 +14,22 @@ import (
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email,hashed_password)
+INSERT INTO users (id, created_at, updated_at, email,hashed_password,handle)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
-RETURNING id,created_at,updated_at,email,is_chirpy_red
+RETURNING id,created_at,updated_at,email,is_chirpy_red,handle
 `
 
 type CreateUserParams struct {
 	Email          string
 	HashedPassword string
+	Handle         sql.NullString
 }
 
 type CreateUserRow struct {
@@ -36,10 +38,11 @@ type CreateUserRow struct {
 	UpdatedAt   time.Time
 	Email       string
 	IsChirpyRed sql.NullBool
+	Handle      sql.NullString
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword, arg.Handle)
 	var i CreateUserRow
 	err := row.Scan(
 		&i.ID,
@@ -47,6 +50,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateU
 		&i.UpdatedAt,
 		&i.Email,
 		&i.IsChirpyRed,
+		&i.Handle,
 	)
 	return i, err
 }
@@ -61,7 +65,7 @@ func (q *Queries) DeleteAllUsers(ctx context.Context) error {
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email,hashed_password,is_chirpy_red
+SELECT id, created_at, updated_at, email,hashed_password,is_chirpy_red,handle, role, suspended_at
 FROM users
 WHERE email =$1
 `
@@ -76,23 +80,210 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.Role,
+		&i.SuspendedAt,
 	)
 	return i, err
 }
 
-const updateUser = `-- name: UpdateUser :one
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, role, suspended_at
+FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.Role,
+		&i.SuspendedAt,
+	)
+	return i, err
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, role, suspended_at
+FROM users
+WHERE (
+    $1::text IS NULL
+    OR strpos(lower(email), lower($1::text)) > 0
+    OR strpos(lower(handle), lower($1::text)) > 0
+  )
+  AND ($2::text IS NULL OR role = $2::text)
+  AND ($3::boolean IS NULL OR (suspended_at IS NOT NULL) = $3::boolean)
+  AND (
+    $4::timestamp IS NULL
+    OR (created_at, id) < ($4::timestamp, $5::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $6
+`
+
+type ListUsersParams struct {
+	Query           sql.NullString
+	Role            sql.NullString
+	Suspended       sql.NullBool
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	RowLimit        int32
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers,
+		arg.Query,
+		arg.Role,
+		arg.Suspended,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.Handle,
+			&i.Role,
+			&i.SuspendedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockUser = `-- name: LockUser :exec
+SELECT id
+FROM users
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) LockUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, lockUser, id)
+	return err
+}
+
+const setUserRole = `-- name: SetUserRole :one
+UPDATE users
+SET role = $1,
+    updated_at = NOW()
+WHERE id = $2
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, role, suspended_at
+`
+
+type SetUserRoleParams struct {
+	Role string
+	ID   uuid.UUID
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserRole, arg.Role, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.Role,
+		&i.SuspendedAt,
+	)
+	return i, err
+}
+
+const suspendUser = `-- name: SuspendUser :one
+UPDATE users
+SET suspended_at = COALESCE(suspended_at, NOW()),
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, role, suspended_at
+`
+
+func (q *Queries) SuspendUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, suspendUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.Role,
+		&i.SuspendedAt,
+	)
+	return i, err
+}
+
+const unsuspendUser = `-- name: UnsuspendUser :one
 UPDATE users
-SET email = $2,
-    hashed_password = $3,
+SET suspended_at = NULL,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id,created_at,updated_at,email,is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, role, suspended_at
+`
+
+func (q *Queries) UnsuspendUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, unsuspendUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.Role,
+		&i.SuspendedAt,
+	)
+	return i, err
+}
+
+const updateUser = `-- name: UpdateUser :one
+UPDATE users
+SET email = $1,
+    hashed_password = $2,
+    handle = COALESCE($3::text, handle),
+    updated_at = NOW()
+WHERE id = $4
+RETURNING id,created_at,updated_at,email,is_chirpy_red,handle
 `
 
 type UpdateUserParams struct {
-	ID             uuid.UUID
 	Email          string
 	HashedPassword string
+	Handle         sql.NullString
+	ID             uuid.UUID
 }
 
 type UpdateUserRow struct {
@@ -101,10 +292,16 @@ type UpdateUserRow struct {
 	UpdatedAt   time.Time
 	Email       string
 	IsChirpyRed sql.NullBool
+	Handle      sql.NullString
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateUserRow, error) {
-	row := q.db.QueryRowContext(ctx, updateUser, arg.ID, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRowContext(ctx, updateUser,
+		arg.Email,
+		arg.HashedPassword,
+		arg.Handle,
+		arg.ID,
+	)
 	var i UpdateUserRow
 	err := row.Scan(
 		&i.ID,
@@ -112,20 +309,43 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateU
 		&i.UpdatedAt,
 		&i.Email,
 		&i.IsChirpyRed,
+		&i.Handle,
 	)
 	return i, err
 }
 
-const upgradeUser = `-- name: UpgradeUser :execrows
+const updateUserHandle = `-- name: UpdateUserHandle :one
 UPDATE users
-SET is_chirpy_red = true
+SET handle = $2,
+    updated_at = NOW()
 WHERE id = $1
+RETURNING id,created_at,updated_at,email,is_chirpy_red,handle
 `
 
-func (q *Queries) UpgradeUser(ctx context.Context, id uuid.UUID) (int64, error) {
-	result, err := q.db.ExecContext(ctx, upgradeUser, id)
-	if err != nil {
-		return 0, err
-	}
-	return result.RowsAffected()
+type UpdateUserHandleParams struct {
+	ID     uuid.UUID
+	Handle sql.NullString
+}
+
+type UpdateUserHandleRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Email       string
+	IsChirpyRed sql.NullBool
+	Handle      sql.NullString
+}
+
+func (q *Queries) UpdateUserHandle(ctx context.Context, arg UpdateUserHandleParams) (UpdateUserHandleRow, error) {
+	row := q.db.QueryRowContext(ctx, updateUserHandle, arg.ID, arg.Handle)
+	var i UpdateUserHandleRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.IsChirpyRed,
+		&i.Handle,
+	)
+	return i, err
 }
@@ -0,0 +1,21 @@
+package filter
+
+import (
+	"context"
+	"os"
+)
+
+// FileSource reads rules from a word list in the format accepted by
+// ParseRules. The file is read again on every load, so edits to it are
+// picked up by the next Reload.
+type FileSource struct {
+	Path string
+}
+
+func (s FileSource) LoadRules(ctx context.Context) ([]Rule, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRules(string(data))
+}
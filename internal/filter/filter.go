@@ -0,0 +1,224 @@
+// Package filter screens chirp bodies against a list of blocked words.
+// Words are compared after Unicode normalisation, so "KERFUFFLE!",
+// "kérfuffle" and "kеrfuffle" with a Cyrillic е all match "kerfuffle".
+package filter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Action says what happens to a chirp that contains a blocked word.
+type Action string
+
+const (
+	// ActionReplace masks the word and lets the chirp through.
+	ActionReplace Action = "replace"
+	// ActionFlag keeps the chirp as written and queues it for review.
+	ActionFlag Action = "flag"
+	// ActionReject refuses the chirp.
+	ActionReject Action = "reject"
+)
+
+// Mask is what replaced words are turned into.
+const Mask = "****"
+
+// ParseAction validates an action name.
+func ParseAction(s string) (Action, error) {
+	switch a := Action(s); a {
+	case ActionReplace, ActionFlag, ActionReject:
+		return a, nil
+	}
+	return "", fmt.Errorf("unknown filter action %q", s)
+}
+
+// severity orders actions so the strictest one found in a body wins.
+func (a Action) severity() int {
+	switch a {
+	case ActionReplace:
+		return 1
+	case ActionFlag:
+		return 2
+	case ActionReject:
+		return 3
+	}
+	return 0
+}
+
+type Rule struct {
+	Word   string
+	Action Action
+}
+
+// Source supplies rules, for example from a file or a database table.
+type Source interface {
+	LoadRules(ctx context.Context) ([]Rule, error)
+}
+
+// Result is the outcome of screening one body.
+type Result struct {
+	// Body is the input with every replace-action word masked.
+	Body string
+	// Action is the strictest action triggered, or "" if nothing matched.
+	Action Action
+	// Matches lists the normalised blocked words found, in order of first
+	// appearance.
+	Matches []string
+}
+
+// Filter holds the current rules. It is safe for concurrent use and the
+// rules can be swapped while requests are being screened.
+type Filter struct {
+	mu    sync.RWMutex
+	rules map[string]Action
+}
+
+func New(rules []Rule) *Filter {
+	f := &Filter{}
+	f.SetRules(rules)
+	return f
+}
+
+// SetRules replaces the rule set. When a word appears more than once the
+// last rule wins. Words that normalise to nothing are ignored.
+func (f *Filter) SetRules(rules []Rule) {
+	m := make(map[string]Action, len(rules))
+	for _, rule := range rules {
+		word := Normalize(rule.Word)
+		if word == "" {
+			continue
+		}
+		m[word] = rule.Action
+	}
+
+	f.mu.Lock()
+	f.rules = m
+	f.mu.Unlock()
+}
+
+// Rules returns the current rules sorted by word.
+func (f *Filter) Rules() []Rule {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	rules := make([]Rule, 0, len(f.rules))
+	for word, action := range f.rules {
+		rules = append(rules, Rule{Word: word, Action: action})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Word < rules[j].Word })
+	return rules
+}
+
+// Reload loads every source in order and installs the merged rules, with
+// later sources overriding earlier ones. If any source fails the current
+// rules are kept.
+func (f *Filter) Reload(ctx context.Context, sources ...Source) error {
+	var merged []Rule
+	for _, source := range sources {
+		rules, err := source.LoadRules(ctx)
+		if err != nil {
+			return err
+		}
+		merged = append(merged, rules...)
+	}
+	f.SetRules(merged)
+	return nil
+}
+
+// Check screens body. Words are the runs of letters, digits and marks
+// between anything else, so punctuation and emoji next to a word do not
+// hide it.
+func (f *Filter) Check(body string) Result {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var out strings.Builder
+	var res Result
+	seen := make(map[string]bool)
+
+	rest := body
+	for rest != "" {
+		start := strings.IndexFunc(rest, isWordRune)
+		if start < 0 {
+			out.WriteString(rest)
+			break
+		}
+		out.WriteString(rest[:start])
+		rest = rest[start:]
+
+		end := strings.IndexFunc(rest, func(r rune) bool { return !isWordRune(r) })
+		if end < 0 {
+			end = len(rest)
+		}
+		token := rest[:end]
+		rest = rest[end:]
+
+		word := Normalize(token)
+		action, blocked := f.rules[word]
+		if !blocked {
+			out.WriteString(token)
+			continue
+		}
+
+		if !seen[word] {
+			seen[word] = true
+			res.Matches = append(res.Matches, word)
+		}
+		if action.severity() > res.Action.severity() {
+			res.Action = action
+		}
+		if action == ActionReplace {
+			out.WriteString(Mask)
+		} else {
+			out.WriteString(token)
+		}
+	}
+
+	res.Body = out.String()
+	return res
+}
+
+// isWordRune reports whether r can be part of a word. Format characters
+// such as zero-width spaces count, so they cannot be used to split a
+// blocked word in two; Normalize drops them again.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || unicode.Is(unicode.Cf, r)
+}
+
+// ParseRules reads a word list: one word per line, optionally followed by
+// an action, with blank lines and lines starting with # ignored. Words
+// without an action are replaced.
+func ParseRules(text string) ([]Rule, error) {
+	var rules []Rule
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: expected a word and an optional action", i+1)
+		}
+
+		rule := Rule{Word: fields[0], Action: ActionReplace}
+		if len(fields) == 2 {
+			action, err := ParseAction(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			rule.Action = action
+		}
+		if !utf8.ValidString(rule.Word) {
+			return nil, errors.New("word list is not valid UTF-8")
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
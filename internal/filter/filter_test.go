@@ -0,0 +1,132 @@
+package filter_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github/anansi-1/Chirpy/internal/filter"
+)
+
+func TestCheck_ReplacesWordsNextToPunctuation(t *testing.T) {
+	f := filter.New([]filter.Rule{{Word: "kerfuffle", Action: filter.ActionReplace}})
+
+	res := f.Check("What a Kerfuffle! (kerfuffle)")
+	if res.Body != "What a ****! (****)" {
+		t.Errorf("unexpected body: %q", res.Body)
+	}
+	if res.Action != filter.ActionReplace {
+		t.Errorf("expected action %q, got %q", filter.ActionReplace, res.Action)
+	}
+	if !reflect.DeepEqual(res.Matches, []string{"kerfuffle"}) {
+		t.Errorf("unexpected matches: %v", res.Matches)
+	}
+}
+
+func TestCheck_NormalizesUnicode(t *testing.T) {
+	f := filter.New([]filter.Rule{{Word: "sharbert", Action: filter.ActionReplace}})
+
+	tests := []string{
+		"SHARBERT",
+		"\uff53\uff48\uff41\uff52\uff42\uff45\uff52\uff54", // full-width
+		"sh\u0430rb\u0435rt", // Cyrillic а and е
+		"sha\u0301rbert",     // combining accent
+		"shar\u200bbert",     // zero-width space
+		"5harb3rt",           // digit substitutions
+	}
+	for _, body := range tests {
+		if res := f.Check(body); res.Body != filter.Mask {
+			t.Errorf("Check(%q) = %q, want %q", body, res.Body, filter.Mask)
+		}
+	}
+}
+
+func TestCheck_LeavesCleanWordsAlone(t *testing.T) {
+	f := filter.New([]filter.Rule{{Word: "fornax", Action: filter.ActionReplace}})
+
+	body := "Fornaxes are not fornax-free, héllo wörld 🙂"
+	res := f.Check(body)
+	if res.Body != "Fornaxes are not ****-free, héllo wörld 🙂" {
+		t.Errorf("unexpected body: %q", res.Body)
+	}
+}
+
+func TestCheck_LeavesNumbersAlone(t *testing.T) {
+	f := filter.New([]filter.Rule{{Word: "sot", Action: filter.ActionReject}})
+
+	if res := f.Check("Order 507 shipped"); res.Action != "" {
+		t.Errorf("Expected a plain number not to match, got %q", res.Action)
+	}
+	if res := f.Check("you 50t"); res.Action != filter.ActionReject {
+		t.Errorf("Expected %q, got %q", filter.ActionReject, res.Action)
+	}
+}
+
+func TestCheck_StrictestActionWins(t *testing.T) {
+	f := filter.New([]filter.Rule{
+		{Word: "fornax", Action: filter.ActionReplace},
+		{Word: "sharbert", Action: filter.ActionFlag},
+		{Word: "kerfuffle", Action: filter.ActionReject},
+	})
+
+	res := f.Check("fornax sharbert")
+	if res.Action != filter.ActionFlag {
+		t.Errorf("expected action %q, got %q", filter.ActionFlag, res.Action)
+	}
+	if res.Body != "**** sharbert" {
+		t.Errorf("flagged words should be kept, got %q", res.Body)
+	}
+
+	res = f.Check("sharbert kerfuffle")
+	if res.Action != filter.ActionReject {
+		t.Errorf("expected action %q, got %q", filter.ActionReject, res.Action)
+	}
+}
+
+func TestSetRules_ReplacesRuleSet(t *testing.T) {
+	f := filter.New([]filter.Rule{{Word: "fornax", Action: filter.ActionReplace}})
+	f.SetRules([]filter.Rule{{Word: "Kerfuffle", Action: filter.ActionReject}})
+
+	if res := f.Check("fornax"); res.Action != "" {
+		t.Errorf("old rule still applied: %+v", res)
+	}
+	if res := f.Check("kerfuffle"); res.Action != filter.ActionReject {
+		t.Errorf("new rule not applied: %+v", res)
+	}
+}
+
+func TestReload_LaterSourcesOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	list := "# blocked words\nfornax\nkerfuffle flag\n\n"
+	if err := os.WriteFile(path, []byte(list), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	f := filter.New(nil)
+	override := staticSource{{Word: "kerfuffle", Action: filter.ActionReject}}
+	if err := f.Reload(context.Background(), filter.FileSource{Path: path}, override); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	want := []filter.Rule{
+		{Word: "fornax", Action: filter.ActionReplace},
+		{Word: "kerfuffle", Action: filter.ActionReject},
+	}
+	if got := f.Rules(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Rules() = %v, want %v", got, want)
+	}
+}
+
+func TestParseRules_RejectsUnknownAction(t *testing.T) {
+	if _, err := filter.ParseRules("fornax obliterate\n"); err == nil {
+		t.Error("expected an error for an unknown action")
+	}
+}
+
+type staticSource []filter.Rule
+
+func (s staticSource) LoadRules(ctx context.Context) ([]filter.Rule, error) {
+	return s, nil
+}
@@ -0,0 +1,64 @@
+package filter
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+var folder = cases.Fold()
+
+// confusables maps characters that look like Latin letters onto them. It
+// covers the Cyrillic and Greek lookalikes that show up in practice rather
+// than the full Unicode confusables table. Keys are already case-folded.
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'һ': 'h', 'н': 'h',
+	'і': 'i', 'ј': 'j', 'к': 'k', 'м': 'm', 'о': 'o', 'р': 'p', 'ԛ': 'q',
+	'ѕ': 's', 'т': 't', 'ц': 'u', 'ѵ': 'v', 'ԝ': 'w', 'х': 'x', 'у': 'y',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v',
+	'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'γ': 'y',
+	// Latin lookalikes outside ASCII
+	'ı': 'i', 'ɡ': 'g', 'ł': 'l', 'ø': 'o',
+}
+
+// leetDigits are digits used in place of letters. They are only mapped in
+// words that also contain a letter, so plain numbers and codes such as
+// "1337" or "404" are left alone.
+var leetDigits = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't',
+}
+
+// Normalize reduces a word to the form blocked words are compared in:
+// compatibility-normalised (NFKC, so full-width and styled letters become
+// plain ones), stripped of accents and invisible format characters,
+// case-folded, and with lookalike characters mapped to Latin letters.
+// Digits are read as letters only in words that contain a letter.
+func Normalize(word string) string {
+	word = norm.NFKC.String(word)
+
+	var b strings.Builder
+	for _, r := range norm.NFD.String(word) {
+		if unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Cf, r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	folded := folder.String(b.String())
+	leet := strings.IndexFunc(folded, unicode.IsLetter) >= 0
+
+	b.Reset()
+	for _, r := range folded {
+		if c, ok := confusables[r]; ok {
+			r = c
+		} else if c, ok := leetDigits[r]; ok && leet {
+			r = c
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github/anansi-1/Chirpy/internal/auth"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// loadKeyring builds the access token keyring. keySpec is a comma-separated
+// list of kid=path entries naming PEM private keys; the first one signs
+// new tokens and the rest only verify. A JWT_SECRET, when set, is kept as
+// an HS256 key so tokens issued with it stay valid; with no keySpec it is
+// also the signing key.
+//
+// To rotate, add the new key at the end of the list and deploy, so every
+// replica can verify it, then move it to the front. Drop the old key once
+// the tokens it signed have expired.
+func loadKeyring(jwtSecret, keySpec string) (*auth.Keyring, error) {
+	var keys []auth.SigningKey
+	for _, entry := range strings.Split(keySpec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, path, ok := strings.Cut(entry, "=")
+		if !ok || kid == "" || path == "" {
+			return nil, fmt.Errorf("invalid signing key entry %q, want kid=path", entry)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := auth.ParsePrivateKeyPEM(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %q: %w", kid, err)
+		}
+		keys = append(keys, key)
+	}
+
+	if jwtSecret != "" {
+		keys = append(keys, auth.NewHMACKey("default", []byte(jwtSecret)))
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys configured")
+	}
+
+	return auth.NewKeyring(keys[0], keys[1:]...)
+}
+
+// handleJWKS publishes the public signing keys so other services can verify
+// access tokens without sharing a secret.
+func (cfg *apiConfig) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	respondWithJSON(w, http.StatusOK, cfg.keyring.JWKS())
+}
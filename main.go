@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"github/anansi-1/Chirpy/internal/auth"
+	"github/anansi-1/Chirpy/internal/blobstore"
 	"github/anansi-1/Chirpy/internal/database"
+	"github/anansi-1/Chirpy/internal/filter"
 	"log"
 	"net/http"
 	"os"
@@ -16,10 +20,17 @@ import (
 
 type apiConfig struct {
 	fileserverHits atomic.Int32
+	db             *sql.DB
 	dbQueries      *database.Queries
 	platform       string
-	tokenSecret    string
+	keyring        *auth.Keyring
 	apiKey         string
+	blobStore      blobstore.Store
+	filter         *filter.Filter
+	filterSources  []filter.Source
+	tiers          TierPolicy
+	webhookSecret  string
+	redGracePeriod time.Duration
 }
 type User struct {
 	ID        uuid.UUID `json:"id"`
@@ -43,11 +54,69 @@ func main() {
 	if platform == "" {
 		log.Fatal("PLATFORM must be set")
 	}
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		log.Fatal("JWT_SECRET environment variable is not set")
+	keyring, err := loadKeyring(os.Getenv("JWT_SECRET"), os.Getenv("JWT_SIGNING_KEYS"))
+	if err != nil {
+		log.Fatalf("Error loading JWT signing keys (set JWT_SECRET or JWT_SIGNING_KEYS): %s", err)
 	}
 	polkaKey := os.Getenv("POLKA_KEY")
+	webhookSecret := os.Getenv("POLKA_WEBHOOK_SECRET")
+
+	trendingInterval := 5 * time.Minute
+	if s := os.Getenv("TRENDING_REFRESH_INTERVAL"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Fatalf("Invalid TRENDING_REFRESH_INTERVAL: %s", err)
+		}
+		if d <= 0 {
+			log.Fatalf("Invalid TRENDING_REFRESH_INTERVAL: must be positive")
+		}
+		trendingInterval = d
+	}
+
+	publishInterval := 30 * time.Second
+	if s := os.Getenv("SCHEDULED_PUBLISH_INTERVAL"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Fatalf("Invalid SCHEDULED_PUBLISH_INTERVAL: %s", err)
+		}
+		if d <= 0 {
+			log.Fatalf("Invalid SCHEDULED_PUBLISH_INTERVAL: must be positive")
+		}
+		publishInterval = d
+	}
+
+	trashRetention := 30 * 24 * time.Hour
+	if s := os.Getenv("CHIRP_TRASH_RETENTION"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Fatalf("Invalid CHIRP_TRASH_RETENTION: %s", err)
+		}
+		if d < 0 {
+			log.Fatalf("Invalid CHIRP_TRASH_RETENTION: must not be negative")
+		}
+		trashRetention = d
+	}
+
+	redGracePeriod := 72 * time.Hour
+	if s := os.Getenv("SUBSCRIPTION_GRACE_PERIOD"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Fatalf("Invalid SUBSCRIPTION_GRACE_PERIOD: %s", err)
+		}
+		if d < 0 {
+			log.Fatalf("Invalid SUBSCRIPTION_GRACE_PERIOD: must not be negative")
+		}
+		redGracePeriod = d
+	}
+
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "./media"
+	}
+	blobStore, err := blobstore.NewLocalStore(mediaDir)
+	if err != nil {
+		log.Fatalf("Error opening media store: %s", err)
+	}
 
 
 	dbConn, err := sql.Open("postgres", dbURL)
@@ -57,10 +126,57 @@ func main() {
 	dbQueries := database.New(dbConn)
 
 	apiConfig := apiConfig{
-		dbQueries:   dbQueries,
-		platform:    platform,
-		tokenSecret: jwtSecret,
-		apiKey:      polkaKey,
+		db:             dbConn,
+		dbQueries:      dbQueries,
+		platform:       platform,
+		keyring:        keyring,
+		apiKey:         polkaKey,
+		blobStore:      blobStore,
+		filter:         filter.New(nil),
+		tiers:          defaultTierPolicy,
+		redGracePeriod: redGracePeriod,
+		webhookSecret:  webhookSecret,
+	}
+
+	// Words from the file come first so that entries managed through the
+	// admin API override them.
+	if path := os.Getenv("FILTER_WORDS_FILE"); path != "" {
+		source := filter.FileSource{Path: path}
+		// A file that can't be read would make every reload fail, so the
+		// admin-managed words would never load either.
+		if _, err := source.LoadRules(context.Background()); err != nil {
+			log.Fatalf("Error loading FILTER_WORDS_FILE: %s", err)
+		}
+		apiConfig.filterSources = append(apiConfig.filterSources, source)
+	}
+	apiConfig.filterSources = append(apiConfig.filterSources, dbFilterSource{queries: dbQueries})
+	if err := apiConfig.reloadFilter(context.Background()); err != nil {
+		log.Printf("Error loading filter words: %s", err)
+	}
+
+	go apiConfig.runTrendingRefresher(context.Background(), trendingInterval)
+	go apiConfig.runScheduledPublisher(context.Background(), publishInterval)
+	go apiConfig.runTrashPurger(context.Background(), trashRetention)
+	go apiConfig.runFilterReloader(context.Background(), filterReloadInterval)
+	go apiConfig.runSubscriptionSweeper(context.Background())
+	go apiConfig.runProcessedWebhookEventPruner(context.Background())
+	go apiConfig.runWebhookDeliverer(context.Background())
+
+	// Routes declare whether they need a caller and with which scope, so
+	// authentication failures look the same on every endpoint.
+	authn := auth.NewAuthenticator(keyring, dbCredentialStore{queries: dbQueries})
+	authMiddleware := auth.NewMiddleware(authn, respondWithError)
+	requireAuth := func(scope auth.Scope, handler http.HandlerFunc) http.Handler {
+		return authMiddleware.Require(scope, handler)
+	}
+	optionalAuth := func(handler http.HandlerFunc) http.Handler {
+		return authMiddleware.Optional(handler)
+	}
+	requireModerator := func(handler http.HandlerFunc) http.Handler {
+		return authMiddleware.RequireRole(auth.RoleModerator, handler)
+	}
+	requireAdmin := func(handler http.HandlerFunc) http.Handler {
+		return authMiddleware.RequireRole(auth.RoleAdmin, handler)
 	}
 
 	mux := http.NewServeMux()
@@ -68,25 +184,73 @@ func main() {
 
 
 	mux.Handle("/app/", fsHandler)
+	mux.Handle("GET /media/{key}", optionalAuth(apiConfig.handleServeMedia))
 	mux.HandleFunc("GET /api/healthz", handleHealthzfunc)
+	mux.HandleFunc("GET /.well-known/jwks.json", apiConfig.handleJWKS)
 
-	mux.HandleFunc("POST /api/polka/webhooks", apiConfig.handleUpgradeWebhook)
+	mux.HandleFunc("POST /api/polka/webhooks", apiConfig.handlePolkaWebhook)
 
 	mux.HandleFunc("POST /api/login", apiConfig.handleLogin)
 	mux.HandleFunc("POST /api/refresh", apiConfig.handleRefreshAccessToken)
 	mux.HandleFunc("POST /api/revoke", apiConfig.handleRevokeRefreshToken)
+	mux.Handle("GET /api/sessions", requireAuth(auth.ScopeCredentials, apiConfig.handleGetSessions))
+	mux.Handle("DELETE /api/sessions", requireAuth(auth.ScopeCredentials, apiConfig.handleDeleteOtherSessions))
+	mux.Handle("DELETE /api/sessions/{sessionID}", requireAuth(auth.ScopeCredentials, apiConfig.handleDeleteSession))
+	mux.Handle("POST /api/tokens", requireAuth(auth.ScopeCredentials, apiConfig.handleCreatePersonalAccessToken))
+	mux.Handle("GET /api/tokens", requireAuth(auth.ScopeCredentials, apiConfig.handleGetPersonalAccessTokens))
+	mux.Handle("DELETE /api/tokens/{tokenID}", requireAuth(auth.ScopeCredentials, apiConfig.handleDeletePersonalAccessToken))
 
 	mux.HandleFunc("POST /api/users", apiConfig.handleCreateUser)
-	mux.HandleFunc("PUT /api/users", apiConfig.handleUpdateUser)
+	mux.Handle("PUT /api/users", requireAuth(auth.ScopeCredentials, apiConfig.handleUpdateUser))
+	mux.Handle("PUT /api/me/handle", requireAuth(auth.ScopeProfileWrite, apiConfig.handleUpdateHandle))
+	mux.Handle("POST /api/users/{userID}/follow", requireAuth(auth.ScopeProfileWrite, apiConfig.handleFollowUser))
+	mux.Handle("DELETE /api/users/{userID}/follow", requireAuth(auth.ScopeProfileWrite, apiConfig.handleUnfollowUser))
+	mux.HandleFunc("GET /api/users/{userID}/followers", apiConfig.handleGetFollowers)
+	mux.HandleFunc("GET /api/users/{userID}/following", apiConfig.handleGetFollowing)
+	mux.Handle("GET /api/users/{userID}/mentions", optionalAuth(apiConfig.handleGetUserMentions))
+	mux.Handle("GET /api/me/limits", requireAuth(auth.ScopeChirpsRead, apiConfig.handleGetMyLimits))
+
+	mux.Handle("GET /api/timeline", requireAuth(auth.ScopeChirpsRead, apiConfig.handleGetTimeline))
+	mux.Handle("GET /api/tags/{tag}/chirps", optionalAuth(apiConfig.handleGetTagChirps))
+	mux.Handle("GET /api/trending", optionalAuth(apiConfig.handleGetTrending))
 	
-	mux.HandleFunc("GET /api/chirps", apiConfig.handleGetChirps)
-	mux.HandleFunc("POST /api/chirps", apiConfig.handleCreateChirp)
-	mux.HandleFunc("POST /api/validate_chirp", handleValidateChirp)
-	mux.HandleFunc("GET /api/chirps/{chirpID}", apiConfig.handleGetChirpsByID)
-	mux.HandleFunc("DELETE /api/chirps/{chirpID}", apiConfig.handleDeleteChirp)
+	mux.Handle("GET /api/chirps", optionalAuth(apiConfig.handleGetChirps))
+	mux.Handle("GET /api/chirps/search", optionalAuth(apiConfig.handleSearchChirps))
+	mux.Handle("POST /api/chirps", requireAuth(auth.ScopeChirpsWrite, apiConfig.handleCreateChirp))
+	mux.Handle("POST /api/chirps/{chirpID}/rechirp", requireAuth(auth.ScopeChirpsWrite, apiConfig.handleCreateRechirp))
+	mux.Handle("POST /api/chirps/{chirpID}/quote", requireAuth(auth.ScopeChirpsWrite, apiConfig.handleCreateQuoteChirp))
+	mux.Handle("POST /api/validate_chirp", optionalAuth(apiConfig.handleValidateChirp))
+	mux.Handle("GET /api/chirps/{chirpID}", optionalAuth(apiConfig.handleGetChirpsByID))
+	mux.Handle("PUT /api/chirps/{chirpID}", requireAuth(auth.ScopeChirpsWrite, apiConfig.handleUpdateChirp))
+	mux.Handle("DELETE /api/chirps/{chirpID}", requireAuth(auth.ScopeChirpsWrite, apiConfig.handleDeleteChirp))
+	mux.Handle("GET /api/chirps/{chirpID}/revisions", optionalAuth(apiConfig.handleGetChirpRevisions))
+	mux.Handle("GET /api/chirps/{chirpID}/replies", optionalAuth(apiConfig.handleGetChirpReplies))
+	mux.Handle("POST /api/chirps/{chirpID}/likes", requireAuth(auth.ScopeChirpsWrite, apiConfig.handleLikeChirp))
+	mux.Handle("DELETE /api/chirps/{chirpID}/likes", requireAuth(auth.ScopeChirpsWrite, apiConfig.handleUnlikeChirp))
+	mux.Handle("POST /api/chirps/{chirpID}/attachments", requireAuth(auth.ScopeChirpsWrite, apiConfig.handleUploadAttachments))
+	mux.Handle("GET /api/chirps/trash", requireAuth(auth.ScopeChirpsRead, apiConfig.handleGetTrash))
+	mux.Handle("POST /api/chirps/{chirpID}/restore", requireAuth(auth.ScopeChirpsWrite, apiConfig.handleRestoreChirp))
+	mux.Handle("GET /api/scheduled_chirps", requireAuth(auth.ScopeChirpsRead, apiConfig.handleGetScheduledChirps))
+	mux.Handle("PUT /api/scheduled_chirps/{chirpID}", requireAuth(auth.ScopeChirpsWrite, apiConfig.handleRescheduleChirp))
+	mux.Handle("DELETE /api/scheduled_chirps/{chirpID}", requireAuth(auth.ScopeChirpsWrite, apiConfig.handleCancelScheduledChirp))
+	mux.Handle("GET /api/moderation/flagged_chirps", requireModerator(apiConfig.handleGetFlaggedChirps))
+	mux.Handle("DELETE /api/moderation/flagged_chirps/{chirpID}", requireModerator(apiConfig.handleDismissChirpFlag))
 	
-	mux.HandleFunc("GET /admin/metrics", apiConfig.handleMetrics)
-	mux.HandleFunc("POST /admin/reset", apiConfig.handleReset)
+	mux.Handle("GET /admin/metrics", requireAdmin(apiConfig.handleMetrics))
+	mux.Handle("POST /admin/reset", requireAdmin(apiConfig.handleReset))
+	mux.Handle("GET /admin/users", requireAdmin(apiConfig.handleGetUsers))
+	mux.Handle("PUT /admin/users/{userID}/role", requireAdmin(apiConfig.handleSetUserRole))
+	mux.Handle("POST /admin/users/{userID}/suspend", requireAdmin(apiConfig.handleSuspendUser))
+	mux.Handle("DELETE /admin/users/{userID}/suspend", requireAdmin(apiConfig.handleUnsuspendUser))
+	mux.Handle("GET /admin/filter/words", requireAdmin(apiConfig.handleGetFilterWords))
+	mux.Handle("PUT /admin/filter/words/{word}", requireAdmin(apiConfig.handlePutFilterWord))
+	mux.Handle("DELETE /admin/filter/words/{word}", requireAdmin(apiConfig.handleDeleteFilterWord))
+	mux.Handle("POST /admin/webhooks", requireAdmin(apiConfig.handleCreateWebhookEndpoint))
+	mux.Handle("GET /admin/webhooks", requireAdmin(apiConfig.handleGetWebhookEndpoints))
+	mux.Handle("DELETE /admin/webhooks/{endpointID}", requireAdmin(apiConfig.handleDeleteWebhookEndpoint))
+	mux.Handle("GET /admin/webhooks/{endpointID}/deliveries", requireAdmin(apiConfig.handleGetWebhookDeliveries))
+	mux.Handle("GET /admin/webhook_deliveries/{deliveryID}", requireAdmin(apiConfig.handleGetWebhookDelivery))
+	mux.Handle("POST /admin/webhook_deliveries/{deliveryID}/redeliver", requireAdmin(apiConfig.handleRedeliverWebhook))
 
 	srv := &http.Server{
 		Addr:    ":" + port,
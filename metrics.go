@@ -39,6 +39,7 @@ func (cfg *apiConfig) handleMetrics(w http.ResponseWriter, r *http.Request) {
 
 func (cfg *apiConfig) handleReset(w http.ResponseWriter, r *http.Request) {
 
+	// Even admins can only wipe the database on a dev server.
 	if cfg.platform != "dev" {
 		respondWithError(w, http.StatusForbidden, "Your are not authorized to acces this")
 		return
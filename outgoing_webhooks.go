@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github/anansi-1/Chirpy/internal/auth"
+	"github/anansi-1/Chirpy/internal/database"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	webhookDeliveryInterval  = 5 * time.Second
+	webhookDeliveryBatchSize = 50
+	webhookMaxAttempts       = 8
+	webhookRetryBase         = 30 * time.Second
+	webhookClientTimeout     = 10 * time.Second
+
+	// webhookLease is how long a claimed batch is hidden from other
+	// replicas while it is being sent. Deliveries are sent one after
+	// another, so it has to cover a whole batch of requests that all time
+	// out, or the end of the batch would be claimed and sent twice.
+	webhookLease = webhookDeliveryBatchSize*webhookClientTimeout + time.Minute
+
+	// webhookEventRetention is how long events and their delivery history
+	// are kept for inspection.
+	webhookEventRetention = 30 * 24 * time.Hour
+)
+
+// webhookEvents lists the events integrations can subscribe to.
+var webhookEvents = map[string]bool{
+	"chirp.created": true,
+	"chirp.deleted": true,
+	"user.upgraded": true,
+}
+
+var webhookClient = &http.Client{Timeout: webhookClientTimeout}
+
+type WebhookChirp struct {
+	ID              string  `json:"id"`
+	CreatedAt       string  `json:"created_at"`
+	Body            string  `json:"body"`
+	UserID          string  `json:"user_id"`
+	Kind            string  `json:"kind"`
+	InReplyTo       *string `json:"in_reply_to,omitempty"`
+	OriginalChirpID *string `json:"original_chirp_id,omitempty"`
+}
+
+// enqueueWebhookEvent writes an event to the outbox with one pending
+// delivery per subscribed endpoint. It runs inside the transaction that
+// makes the change, so an event is sent if and only if the change commits.
+func enqueueWebhookEvent(ctx context.Context, qtx *database.Queries, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return qtx.EnqueueWebhookEvent(ctx, database.EnqueueWebhookEventParams{
+		Event:   event,
+		Payload: payload,
+	})
+}
+
+// enqueueChirpCreated announces a newly visible chirp. Only public chirps
+// are sent to integrations, and scheduled chirps are announced when they
+// are published.
+func enqueueChirpCreated(ctx context.Context, qtx *database.Queries, chirp database.Chirp) error {
+	if chirp.Visibility != "public" || chirp.PublishAt.Valid {
+		return nil
+	}
+
+	data := WebhookChirp{
+		ID:        chirp.ID.String(),
+		CreatedAt: chirp.CreatedAt.Format(time.RFC3339),
+		Body:      chirp.Body,
+		UserID:    chirp.UserID.UUID.String(),
+		Kind:      chirp.Kind,
+	}
+	if chirp.ParentID.Valid {
+		parentID := chirp.ParentID.UUID.String()
+		data.InReplyTo = &parentID
+	}
+	if chirp.OriginalChirpID.Valid {
+		originalID := chirp.OriginalChirpID.UUID.String()
+		data.OriginalChirpID = &originalID
+	}
+	return enqueueWebhookEvent(ctx, qtx, "chirp.created", data)
+}
+
+func enqueueChirpDeleted(ctx context.Context, qtx *database.Queries, chirp database.Chirp) error {
+	if chirp.Visibility != "public" {
+		return nil
+	}
+	return enqueueWebhookEvent(ctx, qtx, "chirp.deleted", map[string]string{"id": chirp.ID.String()})
+}
+
+// runWebhookDeliverer sends due webhook deliveries every
+// webhookDeliveryInterval until ctx is cancelled, and drops events older
+// than webhookEventRetention. It is started once from main.
+func (cfg *apiConfig) runWebhookDeliverer(ctx context.Context) {
+	ticker := time.NewTicker(webhookDeliveryInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Time{}
+	for {
+		for {
+			sent, err := cfg.deliverDueWebhooks(ctx)
+			if err != nil {
+				log.Printf("Error delivering webhooks: %s", err)
+				break
+			}
+			if sent < webhookDeliveryBatchSize {
+				break
+			}
+		}
+
+		if time.Since(lastCleanup) > time.Hour {
+			if _, err := cfg.dbQueries.DeleteOldWebhookEvents(ctx, time.Now().UTC().Add(-webhookEventRetention)); err != nil {
+				log.Printf("Error deleting old webhook events: %s", err)
+			}
+			lastCleanup = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverDueWebhooks sends one batch of due deliveries and returns how many
+// it claimed. Claiming pushes next_attempt_at out by webhookLease, so a
+// replica that dies mid-send leaves the delivery to be retried later
+// rather than lost.
+func (cfg *apiConfig) deliverDueWebhooks(ctx context.Context) (int, error) {
+	deliveries, err := cfg.dbQueries.ClaimDueWebhookDeliveries(ctx, database.ClaimDueWebhookDeliveriesParams{
+		LeaseUntil: time.Now().UTC().Add(webhookLease),
+		BatchSize:  webhookDeliveryBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		statusCode, sendErr := sendWebhook(ctx, delivery)
+		if err := cfg.recordWebhookAttempt(ctx, delivery, statusCode, sendErr); err != nil {
+			return 0, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// sendWebhook posts one delivery, signed with the endpoint's secret. The
+// envelope id is the outbox event id, which stays the same across retries
+// so receivers can de-duplicate.
+func sendWebhook(ctx context.Context, delivery database.ClaimDueWebhookDeliveriesRow) (int, error) {
+	type WebhookEnvelope struct {
+		ID        string          `json:"id"`
+		Event     string          `json:"event"`
+		CreatedAt string          `json:"created_at"`
+		Data      json.RawMessage `json:"data"`
+	}
+
+	body, err := json.Marshal(WebhookEnvelope{
+		ID:        delivery.OutboxID.String(),
+		Event:     delivery.Event,
+		CreatedAt: delivery.CreatedAt.Format(time.RFC3339),
+		Data:      delivery.Payload,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Webhook-Id", delivery.OutboxID.String())
+	req.Header.Set(auth.WebhookTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(auth.WebhookSignatureHeader, auth.SignWebhook(delivery.Secret, now, body))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// recordWebhookAttempt logs an attempt and moves the delivery on: done on
+// success, otherwise retried with exponential backoff until
+// webhookMaxAttempts is reached.
+func (cfg *apiConfig) recordWebhookAttempt(ctx context.Context, delivery database.ClaimDueWebhookDeliveriesRow, statusCode int, sendErr error) error {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	attempt := database.CreateWebhookDeliveryAttemptParams{DeliveryID: delivery.ID}
+	if statusCode != 0 {
+		attempt.StatusCode = sql.NullInt32{Int32: int32(statusCode), Valid: true}
+	}
+	if sendErr != nil {
+		attempt.Error = sql.NullString{String: sendErr.Error(), Valid: true}
+	}
+	if err := qtx.CreateWebhookDeliveryAttempt(ctx, attempt); err != nil {
+		return err
+	}
+
+	attempts := int(delivery.Attempts) + 1
+	update := database.UpdateWebhookDeliveryParams{
+		Status:        "succeeded",
+		NextAttemptAt: time.Now().UTC(),
+		ID:            delivery.ID,
+	}
+	if sendErr != nil {
+		update.Status = "pending"
+		update.NextAttemptAt = time.Now().UTC().Add(webhookBackoff(attempts))
+		if attempts >= webhookMaxAttempts {
+			update.Status = "failed"
+		}
+	}
+	if err := qtx.UpdateWebhookDelivery(ctx, update); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// webhookBackoff is the wait after the given number of failed attempts:
+// 30s, 1m, 2m, 4m and so on.
+func webhookBackoff(attempts int) time.Duration {
+	return webhookRetryBase << (attempts - 1)
+}
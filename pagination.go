@@ -0,0 +1,99 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// pageCursor marks the last row of a page. Pages are ordered by
+// (created_at, id), so the pair is enough to resume from the next row.
+type pageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func encodeCursor(c pageCursor) string {
+	raw := c.CreatedAt.Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageCursor{}, errors.New("cursor is not valid base64")
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return pageCursor{}, errors.New("malformed cursor timestamp")
+	}
+
+	rowID, err := uuid.Parse(id)
+	if err != nil {
+		return pageCursor{}, errors.New("malformed cursor id")
+	}
+
+	return pageCursor{CreatedAt: t, ID: rowID}, nil
+}
+
+// cursorParams turns the cursor query parameter into the nullable
+// arguments taken by the keyset queries. An empty cursor means the
+// first page.
+func cursorParams(s string) (sql.NullTime, uuid.NullUUID, error) {
+	if s == "" {
+		return sql.NullTime{}, uuid.NullUUID{}, nil
+	}
+
+	cursor, err := decodeCursor(s)
+	if err != nil {
+		return sql.NullTime{}, uuid.NullUUID{}, err
+	}
+
+	return sql.NullTime{Time: cursor.CreatedAt, Valid: true}, uuid.NullUUID{UUID: cursor.ID, Valid: true}, nil
+}
+
+// parsePageLimit reads the limit query parameter, falling back to the
+// default when it is absent and capping it at maxPageLimit.
+func parsePageLimit(s string) (int, error) {
+	if s == "" {
+		return defaultPageLimit, nil
+	}
+
+	limit, err := strconv.Atoi(s)
+	if err != nil || limit < 1 {
+		return 0, errors.New("limit must be a positive integer")
+	}
+
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	return limit, nil
+}
+
+// setNextLink adds an RFC 8288 Link header pointing at the next page,
+// keeping every other query parameter of the current request.
+func setNextLink(w http.ResponseWriter, r *http.Request, next pageCursor) {
+	query := r.URL.Query()
+	query.Set("cursor", encodeCursor(next))
+
+	nextURL := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	w.Header().Set("Link", "<"+nextURL.String()+`>; rel="next"`)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github/anansi-1/Chirpy/internal/auth"
+	"github/anansi-1/Chirpy/internal/database"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PersonalAccessTokenResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	Token      string   `json:"token,omitempty"`
+	ExpiresAt  *string  `json:"expires_at"`
+	LastUsedAt *string  `json:"last_used_at"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+func newPersonalAccessTokenResponse(token database.PersonalAccessToken) PersonalAccessTokenResponse {
+	resp := PersonalAccessTokenResponse{
+		ID:        token.ID.String(),
+		Name:      token.Name,
+		Scopes:    token.Scopes,
+		CreatedAt: token.CreatedAt.Format(time.RFC3339),
+	}
+	if token.ExpiresAt.Valid {
+		expiresAt := token.ExpiresAt.Time.Format(time.RFC3339)
+		resp.ExpiresAt = &expiresAt
+	}
+	if token.LastUsedAt.Valid {
+		lastUsedAt := token.LastUsedAt.Time.Format(time.RFC3339)
+		resp.LastUsedAt = &lastUsedAt
+	}
+	return resp
+}
+
+// handleCreatePersonalAccessToken issues a token for bots and scripts.
+// Only its hash is stored, so the token itself is returned just this once.
+func (cfg *apiConfig) handleCreatePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	type PersonalAccessTokenRequest struct {
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	var req PersonalAccessTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		respondWithError(w, http.StatusBadRequest, "name must be 1-100 characters")
+		return
+	}
+
+	if len(req.Scopes) == 0 {
+		respondWithError(w, http.StatusBadRequest, "At least one scope is required")
+		return
+	}
+	scopes, err := auth.ParseScopes(req.Scopes)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Scopes must be chirps:read, chirps:write or profile:write")
+		return
+	}
+
+	var expiresAt sql.NullTime
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			respondWithError(w, http.StatusBadRequest, "expires_at must be in the future")
+			return
+		}
+		expiresAt = sql.NullTime{Time: req.ExpiresAt.UTC(), Valid: true}
+	}
+
+	tokenStr, err := auth.MakePersonalAccessToken()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create token")
+		return
+	}
+
+	scopeNames := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scopeNames = append(scopeNames, string(scope))
+	}
+
+	token, err := cfg.dbQueries.CreatePersonalAccessToken(r.Context(), database.CreatePersonalAccessTokenParams{
+		UserID:    principal.UserID,
+		Name:      name,
+		TokenHash: auth.HashPersonalAccessToken(tokenStr),
+		Scopes:    scopeNames,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create token")
+		return
+	}
+
+	resp := newPersonalAccessTokenResponse(token)
+	resp.Token = tokenStr
+	respondWithJSON(w, http.StatusCreated, resp)
+}
+
+func (cfg *apiConfig) handleGetPersonalAccessTokens(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	tokens, err := cfg.dbQueries.ListPersonalAccessTokens(r.Context(), principal.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting tokens")
+		return
+	}
+
+	resp := make([]PersonalAccessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		resp = append(resp, newPersonalAccessTokenResponse(token))
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handleDeletePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	tokenID, err := uuid.Parse(r.PathValue("tokenID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid token ID format")
+		return
+	}
+
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	deleted, err := cfg.dbQueries.DeletePersonalAccessToken(r.Context(), database.DeletePersonalAccessTokenParams{
+		ID:     tokenID,
+		UserID: principal.UserID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete token")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Token not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
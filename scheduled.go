@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github/anansi-1/Chirpy/internal/auth"
+	"github/anansi-1/Chirpy/internal/database"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const scheduledPublishBatchSize = 100
+
+// runScheduledPublisher publishes due chirps every interval until ctx is
+// cancelled. It is started once from main.
+func (cfg *apiConfig) runScheduledPublisher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			published, err := cfg.publishDueChirps(ctx)
+			if err != nil {
+				log.Printf("Error publishing scheduled chirps: %s", err)
+				break
+			}
+			if published < scheduledPublishBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishDueChirps publishes one batch of chirps whose publish_at has
+// passed and returns how many it published. Rows are claimed with
+// FOR UPDATE SKIP LOCKED, so replicas running the publisher at the same
+// time split the work instead of publishing a chirp twice.
+func (cfg *apiConfig) publishDueChirps(ctx context.Context) (int, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	chirps, err := qtx.PublishDueChirps(ctx, scheduledPublishBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, chirp := range chirps {
+		if chirp.ParentID.Valid {
+			if err := qtx.IncrementReplyCount(ctx, chirp.ParentID.UUID); err != nil {
+				return 0, err
+			}
+		}
+		if err := enqueueChirpCreated(ctx, qtx, chirp); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(chirps), nil
+}
+
+func (cfg *apiConfig) handleGetScheduledChirps(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+
+	chirpRows, err := cfg.dbQueries.ListScheduledChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting scheduled chirps")
+		return
+	}
+
+	chirps, err := cfg.newChirpResponses(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, chirpRows)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting scheduled chirps")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chirps)
+}
+
+func (cfg *apiConfig) handleRescheduleChirp(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	type RescheduleRequest struct {
+		PublishAt time.Time `json:"publish_at"`
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID format")
+		return
+	}
+
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+
+	var req RescheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if !req.PublishAt.After(time.Now()) {
+		respondWithError(w, http.StatusBadRequest, "publish_at must be in the future")
+		return
+	}
+
+	// Only the owner's still-pending chirps match, so a chirp that was
+	// published in the meantime is reported as not found.
+	chirp, err := cfg.dbQueries.RescheduleChirp(r.Context(), database.RescheduleChirpParams{
+		ID:        chirpID,
+		UserID:    uuid.NullUUID{UUID: userID, Valid: true},
+		PublishAt: sql.NullTime{Time: req.PublishAt.UTC(), Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Scheduled chirp not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to reschedule chirp")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, newChirpResponse(chirp))
+}
+
+func (cfg *apiConfig) handleCancelScheduledChirp(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID format")
+		return
+	}
+
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+
+	cancelled, err := cfg.dbQueries.CancelScheduledChirp(r.Context(), database.CancelScheduledChirpParams{
+		ID:     chirpID,
+		UserID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to cancel scheduled chirp")
+		return
+	}
+	if cancelled == 0 {
+		respondWithError(w, http.StatusNotFound, "Scheduled chirp not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
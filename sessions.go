@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"github/anansi-1/Chirpy/internal/auth"
+	"github/anansi-1/Chirpy/internal/database"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SessionResponse struct {
+	ID          string `json:"id"`
+	DeviceLabel string `json:"device_label"`
+	UserAgent   string `json:"user_agent"`
+	IP          string `json:"ip"`
+	CreatedAt   string `json:"created_at"`
+	LastUsedAt  string `json:"last_used_at"`
+	Current     bool   `json:"current"`
+}
+
+// clientIP is the address the request came from. X-Forwarded-For is not
+// trusted since nothing guarantees a proxy in front of the server.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// startSession records a new login and returns its access and refresh
+// tokens. The session ID doubles as the refresh token family.
+func (cfg *apiConfig) startSession(r *http.Request, user database.User, deviceLabel string) (string, string, error) {
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		return "", "", err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	session, err := qtx.CreateSession(r.Context(), database.CreateSessionParams{
+		UserID:      user.ID,
+		DeviceLabel: deviceLabel,
+		UserAgent:   r.UserAgent(),
+		Ip:          clientIP(r),
+		ExpiresAt:   time.Now().UTC().Add(refreshTokenTTL),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err := issueRefreshToken(r.Context(), qtx, user.ID, session.ID)
+	if err != nil {
+		return "", "", err
+	}
+
+	accessToken, err := cfg.keyring.MakeAccessToken(auth.Principal{
+		UserID:    user.ID,
+		SessionID: session.ID,
+		Role:      auth.Role(user.Role),
+	}, time.Hour)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+// revokeSession ends one of userID's sessions along with its refresh
+// tokens. It reports false when there was no such active session.
+func (cfg *apiConfig) revokeSession(ctx context.Context, sessionID, userID uuid.UUID) (bool, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	revoked, err := qtx.RevokeSession(ctx, database.RevokeSessionParams{
+		ID:     sessionID,
+		UserID: userID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if err := qtx.RevokeRefreshTokenFamily(ctx, sessionID); err != nil {
+		return false, err
+	}
+
+	return revoked == 1, tx.Commit()
+}
+
+// revokeOtherSessions ends all of userID's sessions except keepID, along
+// with their refresh tokens. uuid.Nil keeps none.
+func revokeOtherSessions(ctx context.Context, q *database.Queries, userID, keepID uuid.UUID) error {
+	revoked, err := q.RevokeOtherSessions(ctx, database.RevokeOtherSessionsParams{
+		UserID: userID,
+		KeepID: keepID,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, sessionID := range revoked {
+		if err := q.RevokeRefreshTokenFamily(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handleGetSessions(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+	currentID := principal.SessionID
+
+	sessions, err := cfg.dbQueries.ListActiveSessions(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting sessions")
+		return
+	}
+
+	resp := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		resp = append(resp, SessionResponse{
+			ID:          session.ID.String(),
+			DeviceLabel: session.DeviceLabel,
+			UserAgent:   session.UserAgent,
+			IP:          session.Ip,
+			CreatedAt:   session.CreatedAt.Format(time.RFC3339),
+			LastUsedAt:  session.LastUsedAt.Format(time.RFC3339),
+			Current:     session.ID == currentID,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := uuid.Parse(r.PathValue("sessionID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid session ID format")
+		return
+	}
+
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+
+	revoked, err := cfg.revokeSession(r.Context(), sessionID, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to revoke session")
+		return
+	}
+	if !revoked {
+		respondWithError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleDeleteOtherSessions logs the user out everywhere except the
+// session making the request.
+func (cfg *apiConfig) handleDeleteOtherSessions(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+
+	// Tokens from before sessions existed have no session to keep, so this
+	// revokes all of the user's sessions for them.
+	currentID := principal.SessionID
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to revoke sessions")
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	if err := revokeOtherSessions(r.Context(), qtx, userID, currentID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to revoke sessions")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to revoke sessions")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,36 @@
+-- name: CreateAttachment :one
+INSERT INTO attachments (id, created_at, chirp_id, user_id, storage_key, content_type, size_bytes, width, height, position)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
+)
+RETURNING id, created_at, chirp_id, user_id, storage_key, content_type, size_bytes, width, height, position;
+
+-- name: CountChirpAttachments :one
+SELECT COUNT(*)
+FROM attachments
+WHERE chirp_id = $1;
+
+-- name: GetAttachmentByStorageKey :one
+SELECT id, created_at, chirp_id, user_id, storage_key, content_type, size_bytes, width, height, position
+FROM attachments
+WHERE storage_key = $1;
+
+-- name: GetAttachmentsByChirpIDs :many
+SELECT id, created_at, chirp_id, user_id, storage_key, content_type, size_bytes, width, height, position
+FROM attachments
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_id, position;
+
+-- name: DeleteChirpAttachments :many
+DELETE FROM attachments
+WHERE chirp_id = $1
+RETURNING storage_key;
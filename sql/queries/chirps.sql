@@ -1,30 +1,291 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_id, publish_at, visibility)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3,
+    $4,
+    $5
 )
-RETURNING id, created_at, updated_at, body, user_id;
+RETURNING id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at;
+
+-- name: CreateRechirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, kind, original_chirp_id)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at;
 
 -- name: GetAllChirps :many
-SELECT id, created_at, updated_at, body, user_id 
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
 FROM chirps
+WHERE NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL AND visibility = 'public'
 ORDER BY created_at ASC;
 
 -- name: GetChirpsByID :one
-SELECT id, created_at, updated_at, body, user_id 
-FROM chirps 
-WHERE id = $1;
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE id = sqlc.arg('id') AND NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = sqlc.narg('viewer_id')::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = sqlc.narg('viewer_id')::uuid
+    ))
+  );
+
+-- name: GetChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE id = ANY(sqlc.arg('ids')::uuid[]) AND NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = sqlc.narg('viewer_id')::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = sqlc.narg('viewer_id')::uuid
+    ))
+  );
 
 -- name: DeleteChirpsByID :exec
 DELETE FROM chirps
 WHERE id = $1;
 
+-- name: DeleteChirpWithoutReplies :execrows
+DELETE FROM chirps
+WHERE id = $1 AND reply_count = 0;
+
+-- name: TombstoneChirp :exec
+UPDATE chirps
+SET body = '',
+    user_id = NULL,
+    is_tombstone = true,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: IncrementReplyCount :exec
+UPDATE chirps
+SET reply_count = reply_count + 1
+WHERE id = $1;
+
+-- name: DecrementReplyCount :exec
+UPDATE chirps
+SET reply_count = GREATEST(reply_count - 1, 0)
+WHERE id = $1;
+
 -- name: GetChirpsByAuthorID :many
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE user_id = $1 AND NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL AND visibility = 'public'
+ORDER BY created_at ASC;
+
+-- name: ListChirpsAsc :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = sqlc.narg('viewer_id')::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = sqlc.narg('viewer_id')::uuid
+    ))
+  )
+  AND (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id')::uuid)
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListChirpsDesc :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = sqlc.narg('viewer_id')::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = sqlc.narg('viewer_id')::uuid
+    ))
+  )
+  AND (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id')::uuid)
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: SearchChirps :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE to_tsvector('english', body) @@ to_tsquery('english', sqlc.arg('query'))
+  AND NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = sqlc.narg('viewer_id')::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = sqlc.narg('viewer_id')::uuid
+    ))
+  )
+  AND (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id')::uuid)
+ORDER BY ts_rank(to_tsvector('english', body), to_tsquery('english', sqlc.arg('query'))) DESC, created_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: LockChirp :exec
+SELECT id
+FROM chirps
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at;
+
+-- name: GetChirpThread :many
+WITH RECURSIVE thread AS (
+    SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at, 0 AS depth
+    FROM chirps
+    WHERE chirps.id = sqlc.arg('root_id')
+    AND chirps.publish_at IS NULL
+    AND (
+      chirps.visibility = 'public'
+      OR chirps.user_id = sqlc.narg('viewer_id')::uuid
+      OR (chirps.visibility = 'followers' AND chirps.user_id IN (
+        SELECT followee_id
+        FROM follows
+        WHERE follower_id = sqlc.narg('viewer_id')::uuid
+      ))
+    )
+    UNION ALL
+    SELECT c.id, c.created_at, c.updated_at, c.body, c.user_id, c.parent_id, c.reply_count, c.is_tombstone, c.like_count, c.kind, c.original_chirp_id, c.publish_at, c.visibility, c.deleted_at, t.depth + 1
+    FROM chirps c
+    JOIN thread t ON c.parent_id = t.id
+    WHERE t.depth < sqlc.arg('max_depth')::int
+      AND c.publish_at IS NULL
+      AND (
+        c.visibility = 'public'
+        OR c.user_id = sqlc.narg('viewer_id')::uuid
+        OR (c.visibility = 'followers' AND c.user_id IN (
+          SELECT followee_id
+          FROM follows
+          WHERE follower_id = sqlc.narg('viewer_id')::uuid
+        ))
+      )
+)
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at, depth
+FROM thread
+ORDER BY depth ASC, created_at ASC, id ASC;
+
+-- name: IncrementLikeCount :exec
+UPDATE chirps
+SET like_count = like_count + 1
+WHERE id = $1;
+
+-- name: DecrementLikeCount :exec
+UPDATE chirps
+SET like_count = GREATEST(like_count - 1, 0)
+WHERE id = $1;
+
+-- name: ListTimeline :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE user_id IN (
+    SELECT followee_id
+    FROM follows
+    WHERE follower_id = sqlc.arg('follower_id')
+  )
+  AND NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = sqlc.narg('viewer_id')::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = sqlc.narg('viewer_id')::uuid
+    ))
+  )
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListChirpsByTag :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE id IN (
+    SELECT chirp_tags.chirp_id
+    FROM chirp_tags
+    JOIN tags ON tags.id = chirp_tags.tag_id
+    WHERE tags.name = sqlc.arg('tag')
+  )
+  AND NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = sqlc.narg('viewer_id')::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = sqlc.narg('viewer_id')::uuid
+    ))
+  )
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListChirpsMentioningUser :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE id IN (
+    SELECT chirp_id
+    FROM chirp_mentions
+    WHERE chirp_mentions.user_id = sqlc.arg('mentioned_user_id')
+  )
+  AND NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+  AND (
+    visibility = 'public'
+    OR user_id = sqlc.narg('viewer_id')::uuid
+    OR (visibility = 'followers' AND user_id IN (
+      SELECT followee_id
+      FROM follows
+      WHERE follower_id = sqlc.narg('viewer_id')::uuid
+    ))
+  )
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: CountChirpsByUserSince :one
+SELECT COUNT(*)
 FROM chirps
-WHERE user_id = $1
-ORDER BY created_at ASC;
\ No newline at end of file
+WHERE user_id = sqlc.arg('user_id') AND created_at >= sqlc.arg('since')::timestamp;
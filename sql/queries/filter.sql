@@ -0,0 +1,43 @@
+-- name: ListFilterWords :many
+SELECT word, action, created_at, updated_at
+FROM filter_words
+ORDER BY word ASC;
+
+-- name: UpsertFilterWord :one
+INSERT INTO filter_words (word, action, created_at, updated_at)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    NOW()
+)
+ON CONFLICT (word) DO UPDATE
+SET action = EXCLUDED.action,
+    updated_at = NOW()
+RETURNING word, action, created_at, updated_at;
+
+-- name: DeleteFilterWord :execrows
+DELETE FROM filter_words
+WHERE word = $1;
+
+-- name: FlagChirp :exec
+INSERT INTO flagged_chirps (chirp_id, matched_words, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (chirp_id) DO UPDATE
+SET matched_words = EXCLUDED.matched_words,
+    created_at = NOW();
+
+-- name: ListFlaggedChirps :many
+SELECT flagged_chirps.chirp_id, flagged_chirps.matched_words, flagged_chirps.created_at, chirps.body, chirps.user_id
+FROM flagged_chirps
+JOIN chirps ON chirps.id = flagged_chirps.chirp_id
+WHERE chirps.deleted_at IS NULL AND NOT chirps.is_tombstone
+ORDER BY flagged_chirps.created_at ASC;
+
+-- name: DismissChirpFlag :execrows
+DELETE FROM flagged_chirps
+WHERE chirp_id = $1;
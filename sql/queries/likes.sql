@@ -0,0 +1,14 @@
+-- name: CreateLike :execrows
+INSERT INTO likes (user_id, chirp_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (user_id, chirp_id) DO NOTHING;
+
+-- name: DeleteLike :execrows
+DELETE FROM likes
+WHERE user_id = $1 AND chirp_id = $2;
+
+-- name: GetLikedChirpIDs :many
+SELECT chirp_id
+FROM likes
+WHERE user_id = sqlc.arg('user_id')
+  AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
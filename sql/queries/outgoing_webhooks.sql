@@ -0,0 +1,92 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (id, url, secret, events, created_at, updated_at)
+VALUES (gen_random_uuid(), $1, $2, $3, NOW(), NOW())
+RETURNING id, url, secret, events, created_at, updated_at;
+
+-- name: ListWebhookEndpoints :many
+SELECT id, url, secret, events, created_at, updated_at
+FROM webhook_endpoints
+ORDER BY created_at ASC;
+
+-- name: DeleteWebhookEndpoint :execrows
+DELETE FROM webhook_endpoints
+WHERE id = $1;
+
+-- name: EnqueueWebhookEvent :exec
+WITH outbox AS (
+    INSERT INTO webhook_outbox (id, event, payload, created_at)
+    VALUES (gen_random_uuid(), sqlc.arg('event'), sqlc.arg('payload'), NOW())
+    RETURNING id, event
+)
+INSERT INTO webhook_deliveries (id, outbox_id, endpoint_id, status, attempts, next_attempt_at, created_at, updated_at)
+SELECT gen_random_uuid(), outbox.id, webhook_endpoints.id, 'pending', 0, NOW(), NOW(), NOW()
+FROM outbox
+JOIN webhook_endpoints ON outbox.event = ANY(webhook_endpoints.events);
+
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = sqlc.arg('lease_until'),
+    updated_at = NOW()
+FROM webhook_outbox, webhook_endpoints
+WHERE webhook_deliveries.id IN (
+        SELECT id FROM webhook_deliveries
+        WHERE status = 'pending'
+          AND next_attempt_at <= NOW()
+        ORDER BY next_attempt_at
+        LIMIT sqlc.arg('batch_size')
+        FOR UPDATE SKIP LOCKED
+    )
+  AND webhook_outbox.id = webhook_deliveries.outbox_id
+  AND webhook_endpoints.id = webhook_deliveries.endpoint_id
+RETURNING webhook_deliveries.id, webhook_deliveries.attempts, webhook_outbox.id AS outbox_id,
+    webhook_outbox.event, webhook_outbox.payload, webhook_outbox.created_at,
+    webhook_endpoints.url, webhook_endpoints.secret;
+
+-- name: CreateWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempts (id, delivery_id, status_code, error, created_at)
+VALUES (gen_random_uuid(), sqlc.arg('delivery_id'), sqlc.narg('status_code'), sqlc.narg('error'), NOW());
+
+-- name: UpdateWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET status = sqlc.arg('status'),
+    attempts = attempts + 1,
+    next_attempt_at = sqlc.arg('next_attempt_at'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id');
+
+-- name: RedeliverWebhook :one
+UPDATE webhook_deliveries
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, outbox_id, endpoint_id, status, attempts, next_attempt_at, created_at, updated_at;
+
+-- name: ListWebhookDeliveries :many
+SELECT webhook_deliveries.id, webhook_deliveries.outbox_id, webhook_deliveries.endpoint_id, webhook_deliveries.status,
+    webhook_deliveries.attempts, webhook_deliveries.next_attempt_at, webhook_deliveries.created_at,
+    webhook_deliveries.updated_at, webhook_outbox.event
+FROM webhook_deliveries
+JOIN webhook_outbox ON webhook_outbox.id = webhook_deliveries.outbox_id
+WHERE webhook_deliveries.endpoint_id = $1
+ORDER BY webhook_deliveries.created_at DESC
+LIMIT 100;
+
+-- name: GetWebhookDelivery :one
+SELECT webhook_deliveries.id, webhook_deliveries.outbox_id, webhook_deliveries.endpoint_id, webhook_deliveries.status,
+    webhook_deliveries.attempts, webhook_deliveries.next_attempt_at, webhook_deliveries.created_at,
+    webhook_deliveries.updated_at, webhook_outbox.event
+FROM webhook_deliveries
+JOIN webhook_outbox ON webhook_outbox.id = webhook_deliveries.outbox_id
+WHERE webhook_deliveries.id = $1;
+
+-- name: ListWebhookDeliveryAttempts :many
+SELECT id, delivery_id, status_code, error, created_at
+FROM webhook_delivery_attempts
+WHERE delivery_id = $1
+ORDER BY created_at ASC;
+
+-- name: DeleteOldWebhookEvents :execrows
+DELETE FROM webhook_outbox
+WHERE created_at < $1;
@@ -0,0 +1,27 @@
+-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_tokens (id, user_id, name, token_hash, scopes, expires_at, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, NOW())
+RETURNING id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at;
+
+-- name: GetPersonalAccessTokenByHash :one
+SELECT id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at
+FROM personal_access_tokens
+WHERE token_hash = $1
+  AND (expires_at IS NULL OR expires_at > NOW())
+  AND user_id IN (SELECT id FROM users WHERE suspended_at IS NULL);
+
+-- name: TouchPersonalAccessToken :exec
+UPDATE personal_access_tokens
+SET last_used_at = NOW()
+WHERE id = $1
+  AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
+
+-- name: ListPersonalAccessTokens :many
+SELECT id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at
+FROM personal_access_tokens
+WHERE user_id = $1
+ORDER BY created_at ASC;
+
+-- name: DeletePersonalAccessToken :execrows
+DELETE FROM personal_access_tokens
+WHERE id = sqlc.arg('id') AND user_id = sqlc.arg('user_id');
@@ -1,14 +1,14 @@
 -- name: CreateRefreshToken :one
 INSERT INTO refresh_tokens (
-  token, created_at, updated_at, user_id, expires_at, revoked_at
+  token, created_at, updated_at, user_id, expires_at, revoked_at, family_id
 )
 VALUES (
-  $1, NOW(), NOW(), $2, $3, NULL
+  $1, NOW(), NOW(), $2, $3, NULL, $4
 )
-RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at;
+RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at, family_id, replaced_by;
 
 -- name: GetRefreshToken :one
-SELECT token, created_at, updated_at, user_id, expires_at, revoked_at 
+SELECT token, created_at, updated_at, user_id, expires_at, revoked_at, family_id, replaced_by
 FROM refresh_tokens
 WHERE token = $1;
 
@@ -16,4 +16,28 @@ WHERE token = $1;
 UPDATE refresh_tokens
 SET revoked_at = NOW(),
     updated_at = NOW()
-WHERE token = $1 AND revoked_at IS NULL;
\ No newline at end of file
+WHERE token = $1 AND revoked_at IS NULL;
+
+-- name: RotateRefreshToken :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW(),
+    replaced_by = sqlc.arg('replaced_by'),
+    updated_at = NOW()
+WHERE token = sqlc.arg('token') AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW(),
+    updated_at = NOW()
+WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: GetRefreshTokenReplacement :one
+-- Returns the token that replaced $1 if the exchange happened within the
+-- last 30 seconds and the replacement is still the family's live token.
+SELECT next.token, next.created_at, next.updated_at, next.user_id, next.expires_at, next.revoked_at, next.family_id, next.replaced_by
+FROM refresh_tokens prev
+JOIN refresh_tokens next ON next.token = prev.replaced_by
+WHERE prev.token = $1
+  AND prev.revoked_at > NOW() - INTERVAL '30 seconds'
+  AND next.revoked_at IS NULL
+  AND next.expires_at > NOW();
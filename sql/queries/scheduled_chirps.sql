@@ -0,0 +1,38 @@
+-- name: ListScheduledChirps :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE user_id = $1 AND publish_at IS NOT NULL
+ORDER BY publish_at ASC, id ASC;
+
+-- name: RescheduleChirp :one
+UPDATE chirps
+SET publish_at = $3,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2 AND publish_at IS NOT NULL
+RETURNING id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at;
+
+-- name: CancelScheduledChirp :execrows
+DELETE FROM chirps
+WHERE id = $1 AND user_id = $2 AND publish_at IS NOT NULL;
+
+-- name: PublishDueChirps :many
+WITH due AS (
+    SELECT id
+    FROM chirps
+    WHERE publish_at IS NOT NULL AND publish_at <= NOW()
+    ORDER BY publish_at ASC
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE chirps
+SET publish_at = NULL,
+    created_at = NOW(),
+    updated_at = NOW()
+FROM due
+WHERE chirps.id = due.id
+RETURNING chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.reply_count, chirps.is_tombstone, chirps.like_count, chirps.kind, chirps.original_chirp_id, chirps.publish_at, chirps.visibility, chirps.deleted_at;
+
+-- name: CountScheduledChirps :one
+SELECT COUNT(*)
+FROM chirps
+WHERE user_id = $1 AND publish_at IS NOT NULL;
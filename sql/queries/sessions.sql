@@ -0,0 +1,40 @@
+-- name: CreateSession :one
+INSERT INTO sessions (id, user_id, device_label, user_agent, ip, created_at, last_used_at, expires_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, NOW(), NOW(), $5)
+RETURNING id, user_id, device_label, user_agent, ip, created_at, last_used_at, expires_at, revoked_at;
+
+-- name: GetSession :one
+SELECT id, user_id, device_label, user_agent, ip, created_at, last_used_at, expires_at, revoked_at
+FROM sessions
+WHERE id = $1;
+
+-- name: TouchSession :exec
+UPDATE sessions
+SET last_used_at = NOW(),
+    user_agent = sqlc.arg('user_agent'),
+    ip = sqlc.arg('ip'),
+    expires_at = sqlc.arg('expires_at')
+WHERE id = sqlc.arg('id');
+
+-- name: ListActiveSessions :many
+SELECT id, user_id, device_label, user_agent, ip, created_at, last_used_at, expires_at, revoked_at
+FROM sessions
+WHERE user_id = $1
+  AND revoked_at IS NULL
+  AND expires_at > NOW()
+ORDER BY last_used_at DESC;
+
+-- name: RevokeSession :execrows
+UPDATE sessions
+SET revoked_at = NOW()
+WHERE id = sqlc.arg('id')
+  AND user_id = sqlc.arg('user_id')
+  AND revoked_at IS NULL;
+
+-- name: RevokeOtherSessions :many
+UPDATE sessions
+SET revoked_at = NOW()
+WHERE user_id = sqlc.arg('user_id')
+  AND id <> sqlc.arg('keep_id')
+  AND revoked_at IS NULL
+RETURNING id;
@@ -0,0 +1,41 @@
+-- name: SetChirpyRed :exec
+UPDATE users
+SET is_chirpy_red = sqlc.arg('is_chirpy_red'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id');
+
+-- name: UpsertSubscription :exec
+INSERT INTO subscriptions (user_id, expires_at, created_at, updated_at)
+VALUES (sqlc.arg('user_id'), sqlc.narg('expires_at'), NOW(), NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET expires_at = EXCLUDED.expires_at,
+    updated_at = NOW();
+
+-- name: ExtendSubscriptionToGrace :exec
+INSERT INTO subscriptions (user_id, expires_at, created_at, updated_at)
+VALUES (sqlc.arg('user_id'), sqlc.arg('grace_until'), NOW(), NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET expires_at = GREATEST(subscriptions.expires_at, EXCLUDED.expires_at),
+    updated_at = NOW();
+
+-- name: DeleteSubscription :exec
+DELETE FROM subscriptions
+WHERE user_id = $1;
+
+-- name: CreateSubscriptionEvent :exec
+INSERT INTO subscription_events (id, user_id, event, expires_at, created_at)
+VALUES (gen_random_uuid(), sqlc.arg('user_id'), sqlc.arg('event'), sqlc.narg('expires_at'), NOW());
+
+-- name: LapseExpiredSubscriptions :many
+WITH lapsed AS (
+    DELETE FROM subscriptions
+    WHERE expires_at < sqlc.arg('now')
+    RETURNING user_id
+)
+UPDATE users
+SET is_chirpy_red = false,
+    updated_at = NOW()
+FROM lapsed
+WHERE users.id = lapsed.user_id
+  AND users.is_chirpy_red
+RETURNING users.id;
@@ -0,0 +1,26 @@
+-- name: CreateTags :exec
+INSERT INTO tags (id, name)
+SELECT gen_random_uuid(), unnest(sqlc.arg('names')::text[])
+ON CONFLICT (name) DO NOTHING;
+
+-- name: AddChirpTags :exec
+INSERT INTO chirp_tags (chirp_id, tag_id, created_at)
+SELECT sqlc.arg('chirp_id')::uuid, id, NOW()
+FROM tags
+WHERE name = ANY(sqlc.arg('names')::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteChirpTags :exec
+DELETE FROM chirp_tags
+WHERE chirp_id = $1;
+
+-- name: AddChirpMentions :exec
+INSERT INTO chirp_mentions (chirp_id, user_id, created_at)
+SELECT sqlc.arg('chirp_id')::uuid, id, NOW()
+FROM users
+WHERE handle = ANY(sqlc.arg('handles')::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteChirpMentions :exec
+DELETE FROM chirp_mentions
+WHERE chirp_id = $1;
@@ -0,0 +1,24 @@
+-- name: TrashChirp :execrows
+UPDATE chirps
+SET deleted_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreChirp :one
+UPDATE chirps
+SET deleted_at = NULL
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL AND NOT is_tombstone
+RETURNING id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at;
+
+-- name: ListTrashedChirps :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE user_id = $1 AND deleted_at IS NOT NULL AND NOT is_tombstone
+ORDER BY deleted_at DESC, id DESC;
+
+-- name: ClaimExpiredTrash :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, reply_count, is_tombstone, like_count, kind, original_chirp_id, publish_at, visibility, deleted_at
+FROM chirps
+WHERE deleted_at < sqlc.arg('deleted_before')::timestamp AND NOT is_tombstone
+ORDER BY deleted_at ASC
+LIMIT sqlc.arg('batch_size')
+FOR UPDATE SKIP LOCKED;
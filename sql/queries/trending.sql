@@ -0,0 +1,86 @@
+-- name: TryLockTrendingRefresh :one
+SELECT pg_try_advisory_xact_lock(hashtext('chirpy_trending_refresh'));
+
+-- name: DeleteTrendingTags :exec
+DELETE FROM trending_tags
+WHERE time_window = $1;
+
+-- name: DeleteTrendingChirps :exec
+DELETE FROM trending_chirps
+WHERE time_window = $1;
+
+-- name: RefreshTrendingTags :exec
+INSERT INTO trending_tags (time_window, tag, score, chirp_count, refreshed_at)
+SELECT
+    sqlc.arg('time_window')::text,
+    tags.name,
+    SUM(activity.weight * EXP(LN(0.5) * EXTRACT(EPOCH FROM (NOW() - activity.happened_at)) / sqlc.arg('half_life_seconds')::float8)) AS score,
+    COUNT(DISTINCT activity.chirp_id),
+    NOW()
+FROM (
+    SELECT id AS chirp_id, created_at AS happened_at, 1.0 AS weight
+    FROM chirps
+    WHERE created_at >= sqlc.arg('since')::timestamp AND NOT is_tombstone AND publish_at IS NULL AND deleted_at IS NULL
+    UNION ALL
+    SELECT chirp_id, created_at, 1.0
+    FROM likes
+    WHERE created_at >= sqlc.arg('since')::timestamp
+    UNION ALL
+    SELECT parent_id, created_at, 2.0
+    FROM chirps
+    WHERE parent_id IS NOT NULL AND created_at >= sqlc.arg('since')::timestamp AND publish_at IS NULL AND deleted_at IS NULL
+    UNION ALL
+    SELECT original_chirp_id, created_at, 3.0
+    FROM chirps
+    WHERE original_chirp_id IS NOT NULL AND created_at >= sqlc.arg('since')::timestamp AND publish_at IS NULL AND deleted_at IS NULL
+) AS activity
+JOIN chirps ON chirps.id = activity.chirp_id
+JOIN chirp_tags ON chirp_tags.chirp_id = activity.chirp_id
+JOIN tags ON tags.id = chirp_tags.tag_id
+WHERE chirps.visibility = 'public' AND chirps.deleted_at IS NULL
+GROUP BY tags.name
+ORDER BY score DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: RefreshTrendingChirps :exec
+INSERT INTO trending_chirps (time_window, chirp_id, score, refreshed_at)
+SELECT
+    sqlc.arg('time_window')::text,
+    activity.chirp_id,
+    SUM(activity.weight * EXP(LN(0.5) * EXTRACT(EPOCH FROM (NOW() - activity.happened_at)) / sqlc.arg('half_life_seconds')::float8)) AS score,
+    NOW()
+FROM (
+    SELECT chirp_id, created_at AS happened_at, 1.0 AS weight
+    FROM likes
+    WHERE created_at >= sqlc.arg('since')::timestamp
+    UNION ALL
+    SELECT parent_id, created_at, 2.0
+    FROM chirps
+    WHERE parent_id IS NOT NULL AND created_at >= sqlc.arg('since')::timestamp AND publish_at IS NULL AND deleted_at IS NULL
+    UNION ALL
+    SELECT original_chirp_id, created_at, 3.0
+    FROM chirps
+    WHERE original_chirp_id IS NOT NULL AND created_at >= sqlc.arg('since')::timestamp AND publish_at IS NULL AND deleted_at IS NULL
+) AS activity
+JOIN chirps ON chirps.id = activity.chirp_id
+WHERE NOT chirps.is_tombstone AND chirps.visibility = 'public' AND chirps.deleted_at IS NULL
+GROUP BY activity.chirp_id
+ORDER BY score DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: GetTrendingTags :many
+SELECT time_window, tag, score, chirp_count, refreshed_at
+FROM trending_tags
+WHERE time_window = $1
+ORDER BY score DESC
+LIMIT $2;
+
+-- name: GetTrendingChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.reply_count, chirps.is_tombstone, chirps.like_count, chirps.kind, chirps.original_chirp_id, chirps.publish_at, chirps.visibility, chirps.deleted_at
+FROM trending_chirps
+JOIN chirps ON chirps.id = trending_chirps.chirp_id
+WHERE trending_chirps.time_window = $1
+  AND NOT chirps.is_tombstone
+  AND chirps.deleted_at IS NULL
+ORDER BY trending_chirps.score DESC
+LIMIT $2;
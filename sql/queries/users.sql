@@ -1,32 +1,85 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email,hashed_password)
+INSERT INTO users (id, created_at, updated_at, email,hashed_password,handle)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
-RETURNING id,created_at,updated_at,email,is_chirpy_red;
+RETURNING id,created_at,updated_at,email,is_chirpy_red,handle;
 
 -- name: DeleteAllUsers :exec
 DELETE FROM users;
 
 -- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email,hashed_password,is_chirpy_red
+SELECT id, created_at, updated_at, email,hashed_password,is_chirpy_red,handle, role, suspended_at
 FROM users
 WHERE email =$1;
 
 
 -- name: UpdateUser :one
 UPDATE users
-SET email = $2,
-    hashed_password = $3,
+SET email = sqlc.arg('email'),
+    hashed_password = sqlc.arg('hashed_password'),
+    handle = COALESCE(sqlc.narg('handle')::text, handle),
     updated_at = NOW()
-WHERE id = $1
-RETURNING id,created_at,updated_at,email,is_chirpy_red;
+WHERE id = sqlc.arg('id')
+RETURNING id,created_at,updated_at,email,is_chirpy_red,handle;
 
--- name: UpgradeUser :execrows
+-- name: UpdateUserHandle :one
 UPDATE users
-SET is_chirpy_red = true
+SET handle = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id,created_at,updated_at,email,is_chirpy_red,handle;
+
+-- name: GetUserByID :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, role, suspended_at
+FROM users
 WHERE id = $1;
+
+-- name: LockUser :exec
+SELECT id
+FROM users
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListUsers :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, role, suspended_at
+FROM users
+WHERE (
+    sqlc.narg('query')::text IS NULL
+    OR strpos(lower(email), lower(sqlc.narg('query')::text)) > 0
+    OR strpos(lower(handle), lower(sqlc.narg('query')::text)) > 0
+  )
+  AND (sqlc.narg('role')::text IS NULL OR role = sqlc.narg('role')::text)
+  AND (sqlc.narg('suspended')::boolean IS NULL OR (suspended_at IS NOT NULL) = sqlc.narg('suspended')::boolean)
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: SetUserRole :one
+UPDATE users
+SET role = $1,
+    updated_at = NOW()
+WHERE id = $2
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, role, suspended_at;
+
+-- name: SuspendUser :one
+UPDATE users
+SET suspended_at = COALESCE(suspended_at, NOW()),
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, role, suspended_at;
+
+-- name: UnsuspendUser :one
+UPDATE users
+SET suspended_at = NULL,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, role, suspended_at;
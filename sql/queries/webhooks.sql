@@ -0,0 +1,8 @@
+-- name: RecordWebhookEvent :execrows
+INSERT INTO processed_webhook_events (source, event_id, event, processed_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (source, event_id) DO NOTHING;
+
+-- name: DeleteOldProcessedWebhookEvents :execrows
+DELETE FROM processed_webhook_events
+WHERE processed_at < $1;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN parent_id UUID REFERENCES chirps(id) ON DELETE SET NULL,
+ADD COLUMN reply_count INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN is_tombstone BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX chirps_parent_id_created_at_idx ON chirps (parent_id, created_at);
+
+-- +goose Down
+DROP INDEX chirps_parent_id_created_at_idx;
+
+ALTER TABLE chirps
+DROP COLUMN is_tombstone,
+DROP COLUMN reply_count,
+DROP COLUMN parent_id;
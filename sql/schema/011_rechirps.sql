@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN kind TEXT NOT NULL DEFAULT 'chirp'
+    CHECK (kind IN ('chirp', 'rechirp', 'quote')),
+ADD COLUMN original_chirp_id UUID;
+
+CREATE INDEX chirps_original_chirp_id_idx ON chirps (original_chirp_id);
+
+CREATE UNIQUE INDEX chirps_one_rechirp_per_user_idx
+ON chirps (user_id, original_chirp_id)
+WHERE kind = 'rechirp';
+
+-- +goose Down
+DROP INDEX chirps_one_rechirp_per_user_idx;
+DROP INDEX chirps_original_chirp_id_idx;
+
+ALTER TABLE chirps
+DROP COLUMN original_chirp_id,
+DROP COLUMN kind;
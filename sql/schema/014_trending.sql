@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE trending_tags (
+    time_window TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    chirp_count INTEGER NOT NULL,
+    refreshed_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (time_window, tag)
+);
+
+CREATE TABLE trending_chirps (
+    time_window TEXT NOT NULL,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    refreshed_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (time_window, chirp_id)
+);
+
+CREATE INDEX likes_created_at_idx ON likes (created_at);
+
+-- +goose Down
+DROP INDEX likes_created_at_idx;
+DROP TABLE trending_chirps;
+DROP TABLE trending_tags;
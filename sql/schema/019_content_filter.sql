@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE filter_words (
+    word TEXT PRIMARY KEY,
+    action TEXT NOT NULL CHECK (action IN ('replace', 'flag', 'reject')),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO filter_words (word, action, created_at, updated_at)
+VALUES
+    ('kerfuffle', 'replace', NOW(), NOW()),
+    ('sharbert', 'replace', NOW(), NOW()),
+    ('fornax', 'replace', NOW(), NOW());
+
+CREATE TABLE flagged_chirps (
+    chirp_id UUID PRIMARY KEY REFERENCES chirps(id) ON DELETE CASCADE,
+    matched_words TEXT[] NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE flagged_chirps;
+DROP TABLE filter_words;
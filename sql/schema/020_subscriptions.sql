@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE subscriptions (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX subscriptions_expires_at_idx ON subscriptions (expires_at)
+WHERE expires_at IS NOT NULL;
+
+CREATE TABLE subscription_events (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event TEXT NOT NULL,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX subscription_events_user_id_idx ON subscription_events (user_id, created_at);
+
+-- +goose Down
+DROP TABLE subscription_events;
+DROP TABLE subscriptions;
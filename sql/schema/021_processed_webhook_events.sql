@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE processed_webhook_events (
+    source TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    event TEXT NOT NULL,
+    processed_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (source, event_id)
+);
+
+-- +goose Down
+DROP TABLE processed_webhook_events;
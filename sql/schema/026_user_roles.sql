@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN role TEXT NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'moderator', 'admin')),
+ADD COLUMN suspended_at TIMESTAMP;
+
+CREATE INDEX users_created_at_idx ON users (created_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX users_created_at_idx;
+
+ALTER TABLE users
+DROP COLUMN suspended_at,
+DROP COLUMN role;
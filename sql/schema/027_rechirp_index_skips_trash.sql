@@ -0,0 +1,14 @@
+-- +goose Up
+-- A rechirp in the trash no longer stops its author rechirping again.
+DROP INDEX chirps_one_rechirp_per_user_idx;
+
+CREATE UNIQUE INDEX chirps_one_rechirp_per_user_idx
+ON chirps (user_id, original_chirp_id)
+WHERE kind = 'rechirp' AND deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX chirps_one_rechirp_per_user_idx;
+
+CREATE UNIQUE INDEX chirps_one_rechirp_per_user_idx
+ON chirps (user_id, original_chirp_id)
+WHERE kind = 'rechirp';
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"github/anansi-1/Chirpy/internal/database"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const subscriptionSweepInterval = 15 * time.Minute
+
+// subscriptionEvents lists the Polka events that change membership.
+var subscriptionEvents = map[string]bool{
+	"user.upgraded":         true,
+	"user.downgraded":       true,
+	"subscription.renewed":  true,
+	"subscription.refunded": true,
+	"payment.failed":        true,
+}
+
+// applySubscriptionEvent updates a user's membership for one Polka event
+// and records it in the subscription history. expiresAt is when the paid
+// period ends; an upgrade without one never lapses. A failed payment keeps
+// the member on Chirpy Red until the grace period is over, after which the
+// sweep downgrades them unless a renewal arrives first. It returns
+// sql.ErrNoRows when the user does not exist.
+//
+// eventID, when set, is recorded in the same transaction; an event that
+// was already processed is skipped so redelivery is harmless.
+func (cfg *apiConfig) applySubscriptionEvent(ctx context.Context, eventID string, userID uuid.UUID, event string, expiresAt sql.NullTime) error {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	user, err := qtx.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if eventID != "" {
+		recorded, err := qtx.RecordWebhookEvent(ctx, database.RecordWebhookEventParams{
+			Source:  "polka",
+			EventID: eventID,
+			Event:   event,
+		})
+		if err != nil {
+			return err
+		}
+		if recorded == 0 {
+			return nil
+		}
+	}
+
+	switch event {
+	case "user.upgraded", "subscription.renewed":
+		if err := qtx.SetChirpyRed(ctx, database.SetChirpyRedParams{
+			IsChirpyRed: sql.NullBool{Bool: true, Valid: true},
+			ID:          userID,
+		}); err != nil {
+			return err
+		}
+		if err := qtx.UpsertSubscription(ctx, database.UpsertSubscriptionParams{
+			UserID:    userID,
+			ExpiresAt: expiresAt,
+		}); err != nil {
+			return err
+		}
+		if !user.IsChirpyRed.Valid || !user.IsChirpyRed.Bool {
+			if err := enqueueWebhookEvent(ctx, qtx, "user.upgraded", map[string]string{"user_id": userID.String()}); err != nil {
+				return err
+			}
+		}
+
+	case "payment.failed":
+		expiresAt = sql.NullTime{}
+		if user.IsChirpyRed.Valid && user.IsChirpyRed.Bool {
+			graceUntil := time.Now().UTC().Add(cfg.redGracePeriod)
+			if err := qtx.ExtendSubscriptionToGrace(ctx, database.ExtendSubscriptionToGraceParams{
+				UserID:     userID,
+				GraceUntil: graceUntil,
+			}); err != nil {
+				return err
+			}
+			expiresAt = sql.NullTime{Time: graceUntil, Valid: true}
+		}
+
+	case "user.downgraded", "subscription.refunded":
+		if err := qtx.SetChirpyRed(ctx, database.SetChirpyRedParams{
+			IsChirpyRed: sql.NullBool{Bool: false, Valid: true},
+			ID:          userID,
+		}); err != nil {
+			return err
+		}
+		if err := qtx.DeleteSubscription(ctx, userID); err != nil {
+			return err
+		}
+		expiresAt = sql.NullTime{}
+	}
+
+	if err := qtx.CreateSubscriptionEvent(ctx, database.CreateSubscriptionEventParams{
+		UserID:    userID,
+		Event:     event,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// runSubscriptionSweeper downgrades members whose subscription has lapsed,
+// checking every subscriptionSweepInterval until ctx is cancelled. It is
+// what keeps membership correct when a downgrade webhook never arrives. It
+// is started once from main.
+func (cfg *apiConfig) runSubscriptionSweeper(ctx context.Context) {
+	ticker := time.NewTicker(subscriptionSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := cfg.lapseExpiredSubscriptions(ctx); err != nil {
+			log.Printf("Error sweeping lapsed subscriptions: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (cfg *apiConfig) lapseExpiredSubscriptions(ctx context.Context) error {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	lapsed, err := qtx.LapseExpiredSubscriptions(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range lapsed {
+		if err := qtx.CreateSubscriptionEvent(ctx, database.CreateSubscriptionEventParams{
+			UserID: userID,
+			Event:  "subscription.lapsed",
+		}); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
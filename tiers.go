@@ -0,0 +1,114 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"github/anansi-1/Chirpy/internal/auth"
+	"github/anansi-1/Chirpy/internal/database"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TierLimits is everything that differs between a free account and a
+// Chirpy Red one. Handlers read limits from here instead of hard-coding
+// them.
+type TierLimits struct {
+	Tier                   string `json:"tier"`
+	MaxChirpLength         int    `json:"max_chirp_length"`
+	MaxAttachmentsPerChirp int    `json:"max_attachments_per_chirp"`
+	ChirpsPerHour          int    `json:"chirps_per_hour"`
+	ScheduledChirps        bool   `json:"scheduled_chirps"`
+	MaxScheduledChirps     int    `json:"max_scheduled_chirps"`
+}
+
+// TierPolicy holds the limits for each tier.
+type TierPolicy struct {
+	Free TierLimits
+	Red  TierLimits
+}
+
+var defaultTierPolicy = TierPolicy{
+	Free: TierLimits{
+		Tier:                   "free",
+		MaxChirpLength:         140,
+		MaxAttachmentsPerChirp: 4,
+		ChirpsPerHour:          30,
+		ScheduledChirps:        false,
+		MaxScheduledChirps:     0,
+	},
+	Red: TierLimits{
+		Tier:                   "red",
+		MaxChirpLength:         500,
+		MaxAttachmentsPerChirp: 8,
+		ChirpsPerHour:          300,
+		ScheduledChirps:        true,
+		MaxScheduledChirps:     100,
+	},
+}
+
+// forUser picks the limits that apply to user.
+func (p TierPolicy) forUser(user database.User) TierLimits {
+	if user.IsChirpyRed.Valid && user.IsChirpyRed.Bool {
+		return p.Red
+	}
+	return p.Free
+}
+
+// callerLimits loads the limits of the signed-in user. The tier is read
+// from the database on every call so an upgrade takes effect at once. On
+// failure it writes the error response itself and returns false.
+func (cfg *apiConfig) callerLimits(w http.ResponseWriter, r *http.Request, userID uuid.UUID) (TierLimits, bool) {
+	user, err := cfg.dbQueries.GetUserByID(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusUnauthorized, "User not found")
+		return TierLimits{}, false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error loading account limits")
+		return TierLimits{}, false
+	}
+	return cfg.tiers.forUser(user), true
+}
+
+// checkChirpRate enforces the hourly chirp allowance. Chirps are counted
+// in the database so the limit holds across replicas; trashed chirps still
+// count so deleting and reposting does not reset it. It runs inside the
+// transaction that creates the chirp and first locks the user's row, so
+// concurrent posts by one user are counted one after another and cannot
+// all slip under the limit together.
+func (cfg *apiConfig) checkChirpRate(w http.ResponseWriter, r *http.Request, qtx *database.Queries, userID uuid.UUID, limits TierLimits) bool {
+	if err := qtx.LockUser(r.Context(), userID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error checking chirp rate")
+		return false
+	}
+
+	count, err := qtx.CountChirpsByUserSince(r.Context(), database.CountChirpsByUserSinceParams{
+		UserID: uuid.NullUUID{UUID: userID, Valid: true},
+		Since:  time.Now().UTC().Add(-time.Hour),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error checking chirp rate")
+		return false
+	}
+
+	if count >= int64(limits.ChirpsPerHour) {
+		w.Header().Set("Retry-After", "3600")
+		respondWithError(w, http.StatusTooManyRequests, "Hourly chirp limit reached")
+		return false
+	}
+	return true
+}
+
+func (cfg *apiConfig) handleGetMyLimits(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+
+	limits, ok := cfg.callerLimits(w, r, userID)
+	if !ok {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, limits)
+}
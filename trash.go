@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github/anansi-1/Chirpy/internal/auth"
+	"github/anansi-1/Chirpy/internal/database"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	trashPurgeInterval  = time.Hour
+	trashPurgeBatchSize = 100
+)
+
+// runTrashPurger permanently removes chirps that have been in the trash
+// for longer than retention, checking every trashPurgeInterval until ctx
+// is cancelled. It is started once from main.
+func (cfg *apiConfig) runTrashPurger(ctx context.Context, retention time.Duration) {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			purged, err := cfg.purgeExpiredTrash(ctx, time.Now().UTC().Add(-retention))
+			if err != nil {
+				log.Printf("Error purging trashed chirps: %s", err)
+				break
+			}
+			if purged < trashPurgeBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeExpiredTrash removes one batch of chirps trashed before
+// deletedBefore and returns how many it handled. A chirp that still has
+// replies is kept as a tombstone so the thread stays intact. Rows are
+// claimed with FOR UPDATE SKIP LOCKED so replicas never purge the same
+// chirp twice.
+func (cfg *apiConfig) purgeExpiredTrash(ctx context.Context, deletedBefore time.Time) (int, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	chirps, err := qtx.ClaimExpiredTrash(ctx, database.ClaimExpiredTrashParams{
+		DeletedBefore: deletedBefore,
+		BatchSize:     trashPurgeBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var storageKeys []string
+	for _, chirp := range chirps {
+		keys, err := qtx.DeleteChirpAttachments(ctx, chirp.ID)
+		if err != nil {
+			return 0, err
+		}
+		storageKeys = append(storageKeys, keys...)
+
+		deleted, err := qtx.DeleteChirpWithoutReplies(ctx, chirp.ID)
+		if err != nil {
+			return 0, err
+		}
+		if deleted == 0 {
+			if err := qtx.TombstoneChirp(ctx, chirp.ID); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	cfg.deleteBlobs(storageKeys)
+
+	return len(chirps), nil
+}
+
+func (cfg *apiConfig) handleGetTrash(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+
+	viewer := uuid.NullUUID{UUID: userID, Valid: true}
+
+	chirpRows, err := cfg.dbQueries.ListTrashedChirps(r.Context(), viewer)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting trash")
+		return
+	}
+
+	chirps, err := cfg.newChirpResponses(r.Context(), viewer, chirpRows)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting trash")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chirps)
+}
+
+func (cfg *apiConfig) handleRestoreChirp(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format")
+		return
+	}
+
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
+
+	viewer := uuid.NullUUID{UUID: userID, Valid: true}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to restore chirp")
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	// Only the owner's trashed chirps match, so anything else is reported
+	// as not found.
+	chirp, err := qtx.RestoreChirp(r.Context(), database.RestoreChirpParams{
+		ID:     chirpID,
+		UserID: viewer,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Chirp not found in trash")
+		return
+	}
+	if err != nil {
+		// A trashed rechirp cannot come back once the chirp was rechirped
+		// again.
+		if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
+			respondWithError(w, http.StatusConflict, "Chirp already rechirped")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to restore chirp")
+		return
+	}
+
+	if chirp.ParentID.Valid {
+		if err := qtx.IncrementReplyCount(r.Context(), chirp.ParentID.UUID); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to restore chirp")
+			return
+		}
+	}
+
+	// Integrations were told the chirp was deleted, so it is announced
+	// again now that it is back.
+	if err := enqueueChirpCreated(r.Context(), qtx, chirp); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to restore chirp")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to restore chirp")
+		return
+	}
+
+	chirps, err := cfg.newChirpResponses(r.Context(), viewer, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting chirp")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chirps[0])
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"github/anansi-1/Chirpy/internal/database"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	trendingTagLimit   = 20
+	trendingChirpLimit = 20
+)
+
+// trendingWindow is one of the periods GET /api/trending reports on.
+// Activity inside the window loses half its weight every HalfLife, so
+// recent likes, replies and rechirps count for more than older ones.
+type trendingWindow struct {
+	Name     string
+	Span     time.Duration
+	HalfLife time.Duration
+}
+
+var trendingWindows = []trendingWindow{
+	{Name: "1h", Span: time.Hour, HalfLife: 15 * time.Minute},
+	{Name: "24h", Span: 24 * time.Hour, HalfLife: 6 * time.Hour},
+	{Name: "7d", Span: 7 * 24 * time.Hour, HalfLife: 36 * time.Hour},
+}
+
+// runTrendingRefresher recomputes the trending tables every interval
+// until ctx is cancelled. It is started once from main.
+func (cfg *apiConfig) runTrendingRefresher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, window := range trendingWindows {
+			if err := cfg.refreshTrending(ctx, window); err != nil {
+				log.Printf("Error refreshing trending %s: %s", window.Name, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshTrending replaces the stored scores for one window. Readers see
+// either the old or the new ranking, never a half-written one, and when
+// several replicas run the refresher only one of them does the work.
+func (cfg *apiConfig) refreshTrending(ctx context.Context, window trendingWindow) error {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	locked, err := qtx.TryLockTrendingRefresh(ctx)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+
+	since := time.Now().UTC().Add(-window.Span)
+
+	if err := qtx.DeleteTrendingTags(ctx, window.Name); err != nil {
+		return err
+	}
+	err = qtx.RefreshTrendingTags(ctx, database.RefreshTrendingTagsParams{
+		TimeWindow:      window.Name,
+		HalfLifeSeconds: window.HalfLife.Seconds(),
+		Since:           since,
+		RowLimit:        trendingTagLimit,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteTrendingChirps(ctx, window.Name); err != nil {
+		return err
+	}
+	err = qtx.RefreshTrendingChirps(ctx, database.RefreshTrendingChirpsParams{
+		TimeWindow:      window.Name,
+		HalfLifeSeconds: window.HalfLife.Seconds(),
+		Since:           since,
+		RowLimit:        trendingChirpLimit,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (cfg *apiConfig) handleGetTrending(w http.ResponseWriter, r *http.Request) {
+	type TrendingTagResponse struct {
+		Tag         string  `json:"tag"`
+		Score       float64 `json:"score"`
+		ChirpCount  int32   `json:"chirp_count"`
+		RefreshedAt string  `json:"refreshed_at"`
+	}
+
+	type TrendingWindowResponse struct {
+		Window string                `json:"window"`
+		Tags   []TrendingTagResponse `json:"tags"`
+		Chirps []ChirpResponse       `json:"chirps"`
+	}
+
+	windows := trendingWindows
+	if name := r.URL.Query().Get("window"); name != "" {
+		windows = nil
+		for _, window := range trendingWindows {
+			if window.Name == name {
+				windows = append(windows, window)
+			}
+		}
+		if windows == nil {
+			respondWithError(w, http.StatusBadRequest, "Window must be one of 1h, 24h or 7d")
+			return
+		}
+	}
+
+	viewer := cfg.viewerID(r)
+
+	resp := make([]TrendingWindowResponse, 0, len(windows))
+	for _, window := range windows {
+		tagRows, err := cfg.dbQueries.GetTrendingTags(r.Context(), database.GetTrendingTagsParams{
+			TimeWindow: window.Name,
+			Limit:      trendingTagLimit,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error getting trending tags")
+			return
+		}
+
+		chirpRows, err := cfg.dbQueries.GetTrendingChirps(r.Context(), database.GetTrendingChirpsParams{
+			TimeWindow: window.Name,
+			Limit:      trendingChirpLimit,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error getting trending chirps")
+			return
+		}
+
+		chirps, err := cfg.newChirpResponses(r.Context(), viewer, chirpRows)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error getting trending chirps")
+			return
+		}
+
+		tags := make([]TrendingTagResponse, 0, len(tagRows))
+		for _, tag := range tagRows {
+			tags = append(tags, TrendingTagResponse{
+				Tag:         tag.Tag,
+				Score:       tag.Score,
+				ChirpCount:  tag.ChirpCount,
+				RefreshedAt: tag.RefreshedAt.Format(time.RFC3339),
+			})
+		}
+
+		resp = append(resp, TrendingWindowResponse{
+			Window: window.Name,
+			Tags:   tags,
+			Chirps: chirps,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
 	"github/anansi-1/Chirpy/internal/auth"
 	"github/anansi-1/Chirpy/internal/database"
@@ -16,6 +17,7 @@ func (cfg *apiConfig) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 	type createUserRequest struct {
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		Handle   string `json:"handle"`
 	}
 
 	type UserResponse struct {
@@ -23,6 +25,7 @@ func (cfg *apiConfig) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 		CreatedAt   string `json:"created_at"`
 		UpdatedAt   string `json:"updated_at"`
 		Email       string `json:"email"`
+		Handle      string `json:"handle,omitempty"`
 		IsChirpyRed bool   `json:"is_chirpy_red"`
 	}
 
@@ -36,6 +39,12 @@ func (cfg *apiConfig) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, "Email and password are required")
 		return
 	}
+	handle, err := parseHandle(req.Handle)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Handle must be 1-30 letters, digits or underscores")
+		return
+	}
+
 	hashedPassword, err := auth.HashPassword(req.Password)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Error hashing password")
@@ -44,10 +53,11 @@ func (cfg *apiConfig) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 	user, err := cfg.dbQueries.CreateUser(r.Context(), database.CreateUserParams{
 		Email:          req.Email,
 		HashedPassword: hashedPassword,
+		Handle:         handle,
 	})
 	if err != nil {
 		if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
-			respondWithError(w, http.StatusConflict, "Email already exists")
+			respondWithError(w, http.StatusConflict, uniqueViolationMessage(pgErr))
 			return
 		}
 		respondWithError(w, http.StatusBadRequest, "Error creating user")
@@ -59,6 +69,7 @@ func (cfg *apiConfig) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   user.UpdatedAt.Format(time.RFC3339),
 		Email:       user.Email,
+		Handle:      user.Handle.String,
 		IsChirpyRed: user.IsChirpyRed.Bool,
 	}
 
@@ -66,12 +77,35 @@ func (cfg *apiConfig) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 }
 
 
+// parseHandle validates an optional handle from a request body. An empty
+// handle is left NULL on create and unchanged on update.
+func parseHandle(handle string) (sql.NullString, error) {
+	if handle == "" {
+		return sql.NullString{}, nil
+	}
+
+	normalized, err := normalizeHandle(handle)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: normalized, Valid: true}, nil
+}
+
+func uniqueViolationMessage(pgErr *pq.Error) string {
+	if pgErr.Constraint == "users_handle_key" {
+		return "Handle already taken"
+	}
+	return "Email already exists"
+}
+
 func (cfg *apiConfig) handleLogin(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	type UserLoginRequest struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Email       string `json:"email"`
+		Password    string `json:"password"`
+		DeviceLabel string `json:"device_label"`
 	}
 
 	type LoginResponse struct {
@@ -79,7 +113,9 @@ func (cfg *apiConfig) handleLogin(w http.ResponseWriter, r *http.Request) {
 		CreatedAt    string `json:"created_at"`
 		UpdatedAt    string `json:"updated_at"`
 		Email        string `json:"email"`
+		Handle       string `json:"handle,omitempty"`
 		IsChirpyRed  bool   `json:"is_chirpy_red"`
+		Role         string `json:"role"`
 		Token        string `json:"token"`
 		RefreshToken string `json:"refresh_token"`
 	}
@@ -107,26 +143,16 @@ func (cfg *apiConfig) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	accessToken, err := auth.MakeJWT(user.ID, cfg.tokenSecret, time.Hour)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to create JWT")
-		return
-	}
-
-	refreshToken, err := auth.MakeRefreshToken()
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to create refresh token")
+	// The password is checked first so the response doesn't reveal which
+	// accounts are suspended.
+	if user.SuspendedAt.Valid {
+		respondWithError(w, http.StatusForbidden, "Account suspended")
 		return
 	}
 
-	refreshExpiresAt := time.Now().Add(60 * 24 * time.Hour)
-	_, err = cfg.dbQueries.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{
-		Token:     refreshToken,
-		UserID:    user.ID,
-		ExpiresAt: refreshExpiresAt,
-	})
+	accessToken, refreshToken, err := cfg.startSession(r, user, req.DeviceLabel)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to save refresh token")
+		respondWithError(w, http.StatusInternalServerError, "Failed to start session")
 		return
 	}
 
@@ -135,7 +161,9 @@ func (cfg *apiConfig) handleLogin(w http.ResponseWriter, r *http.Request) {
 		CreatedAt:    user.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:    user.UpdatedAt.Format(time.RFC3339),
 		Email:        user.Email,
+		Handle:       user.Handle.String,
 		IsChirpyRed:  user.IsChirpyRed.Bool,
+		Role:         user.Role,
 		Token:        accessToken,
 		RefreshToken: refreshToken,
 	}
@@ -146,21 +174,13 @@ func (cfg *apiConfig) handleLogin(w http.ResponseWriter, r *http.Request) {
 func (cfg *apiConfig) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
-	tokenStr, err := auth.GetBearerToken(r.Header)
-	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Missing or invalid Authorization header")
-		return
-	}
-
-	userID, err := auth.ValidateJWT(tokenStr, cfg.tokenSecret)
-	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Invalid or expired token")
-		return
-	}
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	userID := principal.UserID
 
 	type userUpdateRequest struct {
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		Handle   string `json:"handle"`
 	}
 
 	type UserResponse struct {
@@ -168,6 +188,7 @@ func (cfg *apiConfig) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
 		CreatedAt   string `json:"created_at"`
 		UpdatedAt   string `json:"updated_at"`
 		Email       string `json:"email"`
+		Handle      string `json:"handle,omitempty"`
 		IsChirpyRed bool   `json:"is_chirpy_red"`
 	}
 
@@ -183,6 +204,12 @@ func (cfg *apiConfig) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	handle, err := parseHandle(userUpdate.Handle)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Handle must be 1-30 letters, digits or underscores")
+		return
+	}
+
 	hashedPassword, err := auth.HashPassword(userUpdate.Password)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Error hashing password")
@@ -193,9 +220,14 @@ func (cfg *apiConfig) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
 		ID:             userID,
 		Email:          userUpdate.Email,
 		HashedPassword: hashedPassword,
+		Handle:         handle,
 	})
 
 	if err != nil {
+		if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
+			respondWithError(w, http.StatusConflict, uniqueViolationMessage(pgErr))
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "Failed to Update User info")
 		return
 	}
@@ -205,9 +237,66 @@ func (cfg *apiConfig) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
 		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   user.UpdatedAt.Format(time.RFC3339),
 		Email:       user.Email,
+		Handle:      user.Handle.String,
 		IsChirpyRed: user.IsChirpyRed.Bool,
 	}
 
 	respondWithJSON(w, http.StatusOK, resp)
 
-}
\ No newline at end of file
+}
+
+// handleUpdateHandle changes only the caller's handle. Unlike PUT
+// /api/users it leaves the email and password alone, so it needs just the
+// profile:write scope.
+func (cfg *apiConfig) handleUpdateHandle(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	type handleUpdateRequest struct {
+		Handle string `json:"handle"`
+	}
+
+	type UserResponse struct {
+		ID          string `json:"id"`
+		CreatedAt   string `json:"created_at"`
+		UpdatedAt   string `json:"updated_at"`
+		Email       string `json:"email"`
+		Handle      string `json:"handle,omitempty"`
+		IsChirpyRed bool   `json:"is_chirpy_red"`
+	}
+
+	var req handleUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	handle, err := parseHandle(req.Handle)
+	if err != nil || !handle.Valid {
+		respondWithError(w, http.StatusBadRequest, "Handle must be 1-30 letters, digits or underscores")
+		return
+	}
+
+	user, err := cfg.dbQueries.UpdateUserHandle(r.Context(), database.UpdateUserHandleParams{
+		ID:     principal.UserID,
+		Handle: handle,
+	})
+	if err != nil {
+		if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
+			respondWithError(w, http.StatusConflict, uniqueViolationMessage(pgErr))
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to update handle")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, UserResponse{
+		ID:          user.ID.String(),
+		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   user.UpdatedAt.Format(time.RFC3339),
+		Email:       user.Email,
+		Handle:      user.Handle.String,
+		IsChirpyRed: user.IsChirpyRed.Bool,
+	})
+}